@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/fanchann/docunyan/internals/generator"
 	"github.com/fanchann/docunyan/internals/live"
@@ -19,11 +20,30 @@ var docunyanLogo = `
 `
 
 func Execute() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "schema":
+			executeSchema(os.Args[2:])
+			return
+		case "bundle":
+			executeBundle(os.Args[2:])
+			return
+		case "export":
+			executeExport(os.Args[2:])
+			return
+		}
+	}
+
 	configPath := flag.String("config", "", "Path to docunyan.yml")
 	goFilePath := flag.String("go-file", "", "Path to Go file")
 	outputPath := flag.String("output", "", "Output Swagger file (optional)")
 	livePreview := flag.String("live", "", "Swagger file for live preview (optional)")
 	watchFile := flag.String("watcher", "", "Path to YAML file for live validation")
+	profile := flag.String("profile", "", "Config profile to apply, e.g. dev, staging, prod (optional)")
+	lenient := flag.Bool("lenient", false, "Report unknown config keys as warnings instead of errors")
+	openAPI := flag.String("openapi", "3.0", "OpenAPI version of the generated spec: 3.0, 3.1 or 2.0 (Swagger)")
+	format := flag.String("format", "", "Output format: json, yaml or json,yaml for both (default from --output extension)")
+	split := flag.Bool("split", false, "Write --output as a directory with openapi.yaml, schemas/ and paths/")
 
 	flag.Parse()
 
@@ -33,7 +53,7 @@ func Execute() {
 		return
 
 	case *watchFile != "" && *configPath == "" && *goFilePath == "" && *livePreview == "":
-		w, err := watcher.NewConfigWatcher(*watchFile)
+		w, err := watcher.NewConfigWatcher(*watchFile, *lenient)
 		if err != nil {
 			log.Fatalf("Failed to Watch File: %v\n", err)
 		}
@@ -41,7 +61,13 @@ func Execute() {
 		return
 
 	case *configPath != "" && *goFilePath != "":
-		err := generator.GenerateSwagger(*configPath, *goFilePath, *outputPath)
+		err := generator.GenerateSwagger(*configPath, *goFilePath, *outputPath, generator.Options{
+			Profile: *profile,
+			Lenient: *lenient,
+			Format:  *format,
+			OpenAPI: *openAPI,
+			Split:   *split,
+		})
 		if err != nil {
 			log.Fatalf("Failed to generate Swagger: %v\n", err)
 		}
@@ -54,8 +80,87 @@ func Execute() {
 
 	fmt.Println(docunyanLogo)
 	fmt.Println("Usage:")
-	fmt.Println("  Generate Swagger:         docunyan --config path/to/docunyan.yml --go-file path/to/response.go [--output path/to/swagger.json] [--live path/to/swagger.yaml]")
+	fmt.Println("  Generate Swagger:         docunyan --config path/to/docunyan.yml --go-file path/to/response.go [--output path/to/swagger.json] [--format json|yaml] [--openapi 3.0|3.1|2.0] [--split] [--profile name] [--lenient] [--live path/to/swagger.yaml]")
 	fmt.Println("  Live Preview Only:        docunyan --live path/to/swagger.yaml")
-	fmt.Println("  Realtime YAML Validation: docunyan --watcher path/to/docunyan.yml")
+	fmt.Println("  Realtime YAML Validation: docunyan --watcher path/to/docunyan.yml [--lenient]")
+	fmt.Println("  Config JSON Schema:       docunyan schema [--go-file path/to/response.go] [--output docunyan.schema.json]")
+	fmt.Println("  Bundle Split Spec:        docunyan bundle --input path/to/openapi.yaml [--output path/to/swagger.json] [--format json|yaml]")
+	fmt.Println("  Postman Collection:       docunyan export postman --config path/to/docunyan.yml --go-file path/to/response.go [--output api.postman_collection.json] [--profile name]")
+	fmt.Println("  Static HTML Docs:         docunyan export html (--config path/to/docunyan.yml --go-file path/to/response.go | --spec path/to/swagger.yaml) [--output api.html] [--openapi 3.0|3.1|2.0]")
 	os.Exit(1)
 }
+
+// docunyan schema: writes the JSON Schema of docunyan.yml for editor validation
+func executeSchema(args []string) {
+	schemaFlags := flag.NewFlagSet("schema", flag.ExitOnError)
+	goFilePath := schemaFlags.String("go-file", "", "Go file whose structs are allowed as schema names (optional)")
+	outputPath := schemaFlags.String("output", "", "Output JSON Schema file (default stdout)")
+	_ = schemaFlags.Parse(args)
+
+	if err := generator.GenerateConfigSchema(*goFilePath, *outputPath); err != nil {
+		log.Fatalf("Failed to generate config schema: %v\n", err)
+	}
+}
+
+// docunyan bundle: inlines the files of a split spec into a single document
+func executeBundle(args []string) {
+	bundleFlags := flag.NewFlagSet("bundle", flag.ExitOnError)
+	inputPath := bundleFlags.String("input", "", "Root file of the split spec, e.g. api/openapi.yaml")
+	outputPath := bundleFlags.String("output", "", "Output file (optional)")
+	format := bundleFlags.String("format", "", "Output format: json, yaml or json,yaml for both (default from --output extension)")
+	_ = bundleFlags.Parse(args)
+
+	if *inputPath == "" {
+		bundleFlags.Usage()
+		os.Exit(1)
+	}
+
+	if err := generator.GenerateBundle(*inputPath, *outputPath, *format); err != nil {
+		log.Fatalf("Failed to bundle spec: %v\n", err)
+	}
+}
+
+// docunyan export <kind>: writes the API in another format
+func executeExport(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Println("Usage: docunyan export postman|html --config path/to/docunyan.yml --go-file path/to/response.go [--output file]")
+		os.Exit(1)
+	}
+	kind := args[0]
+
+	exportFlags := flag.NewFlagSet("export "+kind, flag.ExitOnError)
+	configPath := exportFlags.String("config", "", "Path to docunyan.yml")
+	goFilePath := exportFlags.String("go-file", "", "Path to Go file")
+	outputPath := exportFlags.String("output", "", "Output file (optional)")
+	profile := exportFlags.String("profile", "", "Config profile to apply, e.g. dev, staging, prod (optional)")
+	lenient := exportFlags.Bool("lenient", false, "Report unknown config keys as warnings instead of errors")
+	openAPI := exportFlags.String("openapi", "3.0", "OpenAPI version the config is checked against and html renders: 3.0, 3.1 or 2.0 (Swagger)")
+	specPath := exportFlags.String("spec", "", "Existing spec to render instead of --config and --go-file (html only)")
+	_ = exportFlags.Parse(args[1:])
+
+	if kind == "html" && *specPath != "" {
+		if err := generator.ExportSpecHTML(*specPath, *outputPath); err != nil {
+			log.Fatalf("Failed to export %s: %v\n", kind, err)
+		}
+		return
+	}
+
+	if *configPath == "" || *goFilePath == "" {
+		exportFlags.Usage()
+		os.Exit(1)
+	}
+	opts := generator.Options{Profile: *profile, Lenient: *lenient, OpenAPI: *openAPI}
+
+	var err error
+	switch kind {
+	case "postman":
+		err = generator.ExportPostman(*configPath, *goFilePath, *outputPath, opts)
+	case "html":
+		err = generator.ExportHTML(*configPath, *goFilePath, *outputPath, opts)
+	default:
+		log.Fatalf("Unknown export format %q, expected postman or html\n", kind)
+	}
+	if err != nil {
+		log.Fatalf("Failed to export %s: %v\n", kind, err)
+	}
+}
@@ -0,0 +1,80 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/openapi"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// an explicit operationId of a webhook or callback operation
+type outgoingOperation struct {
+	owner       string
+	operationID string
+}
+
+// builds the webhooks section, keyed by event name
+func buildWebhooks(doc models.DocunyanYAML, schemes map[string]models.SecurityScheme) (map[string]*openapi.PathItem, error) {
+	webhooks := map[string]*openapi.PathItem{}
+	for _, name := range utils.SortedKeys(doc.Webhooks) {
+		pathItem, err := buildPathItem(doc, name, doc.Webhooks[name], schemes, nil, true)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", name, err)
+		}
+		webhooks[name] = pathItem
+	}
+	return webhooks, nil
+}
+
+// builds the callbacks of an operation, each keyed by runtime expression
+func buildCallbacks(doc models.DocunyanYAML, callbacks map[string]models.Callback, schemes map[string]models.SecurityScheme) (map[string]openapi.Callback, error) {
+	result := map[string]openapi.Callback{}
+	for _, name := range utils.SortedKeys(callbacks) {
+		callbackObj := openapi.Callback{}
+		for _, expression := range utils.SortedKeys(callbacks[name]) {
+			pathItem, err := buildPathItem(doc, expression, callbacks[name][expression], schemes, nil, true)
+			if err != nil {
+				return nil, fmt.Errorf("callback %s: %w", name, err)
+			}
+			callbackObj[expression] = pathItem
+		}
+		result[name] = callbackObj
+	}
+	return result, nil
+}
+
+// collects the explicit operationIds of webhooks and callbacks in a stable order
+func outgoingOperations(doc models.DocunyanYAML) []outgoingOperation {
+	operations := []outgoingOperation{}
+
+	var collect func(prefix string, items map[string]models.PathItem)
+	collect = func(prefix string, items map[string]models.PathItem) {
+		for _, key := range utils.SortedKeys(items) {
+			for _, method := range utils.SortedKeys(items[key].Operations) {
+				endpoint := items[key].Operations[method]
+				owner := strings.TrimSpace(prefix + " " + strings.ToUpper(method) + " " + key)
+				if endpoint.OperationID != "" {
+					operations = append(operations, outgoingOperation{owner: owner, operationID: endpoint.OperationID})
+				}
+				for _, name := range utils.SortedKeys(endpoint.Callbacks) {
+					collect(owner+" callback "+name+":", endpoint.Callbacks[name])
+				}
+			}
+		}
+	}
+
+	collect("webhook", doc.Webhooks)
+	for _, path := range utils.SortedKeys(doc.Paths) {
+		for _, method := range utils.SortedKeys(doc.Paths[path].Operations) {
+			endpoint := doc.Paths[path].Operations[method]
+			owner := strings.ToUpper(method) + " " + path
+			for _, name := range utils.SortedKeys(endpoint.Callbacks) {
+				collect(owner+" callback "+name+":", endpoint.Callbacks[name])
+			}
+		}
+	}
+
+	return operations
+}
@@ -0,0 +1,164 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/openapi"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// builds a $ref to a named component, full references are kept as they are
+func componentRef(kind, name string) string {
+	if strings.HasPrefix(name, "#") {
+		return name
+	}
+	return "#/components/" + kind + "/" + name
+}
+
+// builds the reusable parameters, responses, headers and request bodies
+func buildComponents(components *models.Components, baseDir string) (*openapi.Components, error) {
+	result := &openapi.Components{Schemas: map[string]*openapi.Schema{}}
+	if components == nil {
+		return result, nil
+	}
+
+	if len(components.Parameters) > 0 {
+		result.Parameters = map[string]*openapi.Parameter{}
+		for name, param := range components.Parameters {
+			result.Parameters[name] = buildParameter(param)
+		}
+	}
+
+	if len(components.Headers) > 0 {
+		result.Headers = map[string]*openapi.Header{}
+		for name, header := range components.Headers {
+			result.Headers[name] = buildHeader(header)
+		}
+	}
+
+	if len(components.Responses) > 0 {
+		result.Responses = map[string]*openapi.Response{}
+		for _, name := range utils.SortedKeys(components.Responses) {
+			response, err := buildResponse(components.Responses[name], baseDir)
+			if err != nil {
+				return nil, fmt.Errorf("components.responses.%s: %w", name, err)
+			}
+			result.Responses[name] = response
+		}
+	}
+
+	if len(components.RequestBodies) > 0 {
+		result.RequestBodies = map[string]*openapi.RequestBody{}
+		for _, name := range utils.SortedKeys(components.RequestBodies) {
+			requestBody, err := buildRequestBody(components.RequestBodies[name], baseDir)
+			if err != nil {
+				return nil, fmt.Errorf("components.requestBodies.%s: %w", name, err)
+			}
+			result.RequestBodies[name] = requestBody
+		}
+	}
+
+	return result, nil
+}
+
+// builds a parameter object or a reference to components.parameters
+func buildParameter(param models.Parameter) *openapi.Parameter {
+	if param.Ref != "" {
+		return &openapi.Parameter{Ref: componentRef("parameters", param.Ref)}
+	}
+
+	return &openapi.Parameter{
+		Name:        param.Name,
+		In:          strings.ToLower(param.In),
+		Description: param.Description,
+		Required:    param.Required,
+		Schema:      buildPrimitiveSchema(param.Type),
+	}
+}
+
+// builds a response header object or a reference to components.headers
+func buildHeader(header models.Header) *openapi.Header {
+	if header.Ref != "" {
+		return &openapi.Header{Ref: componentRef("headers", header.Ref)}
+	}
+
+	return &openapi.Header{
+		Description: header.Description,
+		Required:    header.Required,
+		Schema:      buildPrimitiveSchema(header.Type),
+	}
+}
+
+// builds a response object or a reference to components.responses
+func buildResponse(resp models.Response, baseDir string) (*openapi.Response, error) {
+	if resp.Ref != "" {
+		return &openapi.Response{Ref: componentRef("responses", resp.Ref)}, nil
+	}
+
+	response := &openapi.Response{Description: resp.Description}
+
+	if resp.Schema != "" {
+		mediaType, err := buildMediaType(resp.Schema, resp.Example, resp.Examples, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		response.Content = map[string]*openapi.MediaType{"application/json": mediaType}
+	}
+
+	if len(resp.Headers) > 0 {
+		response.Headers = map[string]*openapi.Header{}
+		for name, header := range resp.Headers {
+			response.Headers[name] = buildHeader(header)
+		}
+	}
+
+	if len(resp.Links) > 0 {
+		response.Links = map[string]*openapi.Link{}
+		for name, link := range resp.Links {
+			response.Links[name] = buildLink(link)
+		}
+	}
+
+	return response, nil
+}
+
+// builds a request body object or a reference to components.requestBodies
+func buildRequestBody(body models.RequestBody, baseDir string) (*openapi.RequestBody, error) {
+	if body.Ref != "" {
+		return &openapi.RequestBody{Ref: componentRef("requestBodies", body.Ref)}, nil
+	}
+
+	mediaType, err := buildMediaType(body.Schema, body.Example, body.Examples, baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	required := true
+	if body.Required != nil {
+		required = *body.Required
+	}
+
+	return &openapi.RequestBody{
+		Description: body.Description,
+		Content:     map[string]*openapi.MediaType{"application/json": mediaType},
+		Required:    required,
+	}, nil
+}
+
+// builds a schema for a simple swagger type, adding the default format
+func buildPrimitiveSchema(swaggerType string) *openapi.Schema {
+	if swaggerType == "" {
+		swaggerType = "string"
+	}
+
+	schema := &openapi.Schema{Type: openapi.SchemaType{swaggerType}}
+	switch swaggerType {
+	case "integer":
+		schema.Format = "int64"
+	case "number":
+		schema.Format = "double"
+	}
+	return schema
+}
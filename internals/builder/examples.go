@@ -0,0 +1,91 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/openapi"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// builds an application/json media type object with schema reference and examples
+func buildMediaType(schema string, example interface{}, examples map[string]models.Example, baseDir string) (*openapi.MediaType, error) {
+	mediaType := &openapi.MediaType{
+		Schema: &openapi.Schema{Ref: "#/components/schemas/" + schema},
+	}
+
+	if example != nil {
+		value, err := resolveExampleValue(example, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		mediaType.Example = value
+	}
+
+	if len(examples) > 0 {
+		mediaType.Examples = map[string]*openapi.Example{}
+		for _, name := range utils.SortedKeys(examples) {
+			exampleObj, err := buildExample(examples[name], baseDir)
+			if err != nil {
+				return nil, fmt.Errorf("example %q: %w", name, err)
+			}
+			mediaType.Examples[name] = exampleObj
+		}
+	}
+
+	return mediaType, nil
+}
+
+// builds a single named example object
+func buildExample(ex models.Example, baseDir string) (*openapi.Example, error) {
+	exampleObj := &openapi.Example{
+		Summary:     ex.Summary,
+		Description: ex.Description,
+	}
+
+	switch {
+	case ex.File != "":
+		value, err := loadExampleFile(ex.File, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		exampleObj.Value = value
+	case ex.ExternalValue != "":
+		exampleObj.ExternalValue = ex.ExternalValue
+	default:
+		exampleObj.Value = utils.ConvertYAMLValue(ex.Value)
+	}
+
+	return exampleObj, nil
+}
+
+// a string ending with .json is treated as a file reference, anything else as an inline value
+func resolveExampleValue(example interface{}, baseDir string) (interface{}, error) {
+	if file, ok := example.(string); ok && strings.HasSuffix(strings.ToLower(file), ".json") {
+		return loadExampleFile(file, baseDir)
+	}
+	return utils.ConvertYAMLValue(example), nil
+}
+
+// loads a JSON example file relative to the config file directory
+func loadExampleFile(file, baseDir string) (interface{}, error) {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read example file %s: %w", file, err)
+	}
+
+	// recorded examples are written back unchanged: key order and large numbers are kept
+	value, err := utils.DecodeOrderedJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON in example file %s: %w", file, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,42 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadExampleFileKeepsValuesUnchanged(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"large integer", `{"id":9007199254740993,"total":12.50}`},
+		{"key order", `{"status":"paid","id":"o1","customer":{"name":"Ann","email":"ann@example.com"}}`},
+		{"array", `[{"zeta":1,"alpha":2},{"id":18446744073709551615}]`},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, "example.json")
+			if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			value, err := loadExampleFile("example.json", dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encoded, []byte(tt.content)) {
+				t.Errorf("example = %s, want %s", encoded, tt.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package builder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/openapi"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// an operation under paths that links can point at
+type linkTarget struct {
+	path     string
+	item     models.PathItem
+	endpoint models.EndpointDetail
+}
+
+// builds a link object, plain field names become response body expressions
+func buildLink(link models.Link) *openapi.Link {
+	linkObj := &openapi.Link{
+		OperationID: link.OperationID,
+		Description: link.Description,
+	}
+
+	if link.OperationRef != "" {
+		linkObj.OperationRef = operationRefPointer(link.OperationRef)
+	}
+
+	if len(link.Parameters) > 0 {
+		linkObj.Parameters = map[string]interface{}{}
+		for name, value := range link.Parameters {
+			linkObj.Parameters[name] = linkExpression(value)
+		}
+	}
+
+	if link.RequestBody != "" {
+		linkObj.RequestBody = linkExpression(link.RequestBody)
+	}
+
+	return linkObj
+}
+
+// turns a response body field into a runtime expression, e.g. "id" -> "$response.body#/id"
+func linkExpression(value string) string {
+	if strings.HasPrefix(value, "$") || strings.HasPrefix(value, "{") {
+		return value
+	}
+	return "$response.body#/" + strings.TrimPrefix(value, "/")
+}
+
+// turns "GET /orders/:id" into "#/paths/~1orders~1{id}/get", other references are kept as is
+func operationRefPointer(ref string) string {
+	method, path, ok := parseOperationRef(ref)
+	if !ok {
+		return ref
+	}
+
+	escaped := strings.ReplaceAll(strings.ReplaceAll(utils.NormalizePathParams(path), "~", "~0"), "/", "~1")
+	return "#/paths/" + escaped + "/" + method
+}
+
+// splits "GET /orders/:id" into its lowercase method and path
+func parseOperationRef(ref string) (string, string, bool) {
+	method, path, found := strings.Cut(strings.TrimSpace(ref), " ")
+	path = strings.TrimSpace(path)
+	if !found || !utils.IsHTTPMethod(method) || !strings.HasPrefix(path, "/") {
+		return "", "", false
+	}
+	return strings.ToLower(method), path, true
+}
+
+// checks that links point at existing operations and parameters, and that the
+// response body fields they read exist in the response schema
+func validateLinks(doc models.DocunyanYAML, schemas map[string]*openapi.Schema) error {
+	operationIDs, _, err := AssignOperationIDs(doc)
+	if err != nil {
+		// reported when building the paths
+		return nil
+	}
+
+	targets := map[string]linkTarget{}
+	for path, methods := range operationIDs {
+		for method, operationID := range methods {
+			item := doc.Paths[path]
+			targets[operationID] = linkTarget{path: path, item: item, endpoint: item.Operations[method]}
+		}
+	}
+
+	return forEachResponse(doc, func(owner string, resp models.Response) error {
+		for _, name := range utils.SortedKeys(resp.Links) {
+			if err := validateLink(doc, resp.Links[name], resp.Schema, targets, schemas); err != nil {
+				return fmt.Errorf("%s link %s: %w", owner, name, err)
+			}
+		}
+		return nil
+	})
+}
+
+func validateLink(doc models.DocunyanYAML, link models.Link, schema string, targets map[string]linkTarget, schemas map[string]*openapi.Schema) error {
+	if (link.OperationID == "") == (link.OperationRef == "") {
+		return fmt.Errorf("exactly one of operationId and operationRef is required")
+	}
+
+	var target *linkTarget
+	if link.OperationID != "" {
+		found, ok := targets[link.OperationID]
+		if !ok {
+			ids := utils.SortedKeys(targets)
+			if suggestion := utils.ClosestMatch(link.OperationID, ids); suggestion != "" {
+				return fmt.Errorf("unknown operationId %q (did you mean %q?)", link.OperationID, suggestion)
+			}
+			return fmt.Errorf("unknown operationId %q", link.OperationID)
+		}
+		target = &found
+	} else if method, path, ok := parseOperationRef(link.OperationRef); ok {
+		configPath, exists := FindConfigPath(doc, path)
+		if !exists {
+			return fmt.Errorf("operationRef %q: unknown path %s", link.OperationRef, path)
+		}
+		item := doc.Paths[configPath]
+		for key, endpoint := range item.Operations {
+			if strings.ToLower(key) == method {
+				target = &linkTarget{path: configPath, item: item, endpoint: endpoint}
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("operationRef %q: path %s has no %s operation", link.OperationRef, path, strings.ToUpper(method))
+		}
+	}
+
+	if target != nil {
+		params := operationParamNames(doc, *target)
+		for _, name := range utils.SortedKeys(link.Parameters) {
+			// parameters may be qualified by location, e.g. path.id
+			bare := name
+			if location, rest, found := strings.Cut(name, "."); found && (location == "path" || location == "query" || location == "header" || location == "cookie") {
+				bare = rest
+			}
+			if !utils.Contains(params, bare) {
+				if suggestion := utils.ClosestMatch(bare, params); suggestion != "" {
+					return fmt.Errorf("operation has no parameter %q (did you mean %q?)", bare, suggestion)
+				}
+				return fmt.Errorf("operation has no parameter %q", bare)
+			}
+		}
+	}
+
+	values := []string{}
+	for _, name := range utils.SortedKeys(link.Parameters) {
+		values = append(values, link.Parameters[name])
+	}
+	if link.RequestBody != "" {
+		values = append(values, link.RequestBody)
+	}
+
+	for _, value := range values {
+		pointer, ok := strings.CutPrefix(linkExpression(value), "$response.body#")
+		if !ok {
+			continue
+		}
+		if err := resolveSchemaPointer(schemas, schema, pointer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// finds the config key of a path written in either form, /orders/:id or /orders/{id}
+func FindConfigPath(doc models.DocunyanYAML, path string) (string, bool) {
+	if _, ok := doc.Paths[path]; ok {
+		return path, true
+	}
+	normalized := utils.NormalizePathParams(path)
+	for _, key := range utils.SortedKeys(doc.Paths) {
+		if utils.NormalizePathParams(key) == normalized {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// names of the path, query and explicit parameters of an operation
+func operationParamNames(doc models.DocunyanYAML, target linkTarget) []string {
+	names := utils.ExtractPathParams(target.path)
+	for _, param := range target.endpoint.Query {
+		names = append(names, param.Name)
+	}
+
+	for _, params := range [][]models.Parameter{target.item.Parameters, target.endpoint.Parameters} {
+		for _, param := range params {
+			if param.Ref != "" && doc.Components != nil {
+				param = doc.Components.Parameters[param.Ref]
+			}
+			names = append(names, param.Name)
+		}
+	}
+
+	switch p := target.endpoint.Parameter.(type) {
+	case string:
+		names = append(names, "body")
+	case map[interface{}]interface{}, map[string]interface{}:
+		paramMap, _ := utils.ConvertYAMLValue(p).(map[string]interface{})
+		if name, ok := paramMap["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// follows a JSON pointer such as /customer/id through the properties of a schema
+func resolveSchemaPointer(schemas map[string]*openapi.Schema, schemaName, pointer string) error {
+	current, ok := schemas[schemaName]
+	if !ok {
+		// primitive or unknown response schemas cannot be checked
+		return nil
+	}
+
+	walked := schemaName
+	for _, segment := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+
+		// follow references to other schemas
+		current = dereferenceSchema(schemas, current)
+
+		if current.Items != nil {
+			if _, err := strconv.Atoi(segment); err != nil {
+				return fmt.Errorf("%s is an array, %q is not an index", walked, segment)
+			}
+			current = current.Items
+		} else {
+			properties := current.Properties
+			if properties == nil {
+				properties = openapi.NewProperties()
+			}
+			property, ok := properties.Get(segment)
+			if !ok {
+				names := properties.Names()
+				if suggestion := utils.ClosestMatch(segment, names); suggestion != "" {
+					return fmt.Errorf("field %q not found in %s (did you mean %q?)", segment, walked, suggestion)
+				}
+				return fmt.Errorf("field %q not found in %s", segment, walked)
+			}
+			current = property
+		}
+		walked += "." + segment
+	}
+
+	return nil
+}
+
+// follows a reference to a component schema, nullable references are wrapped in allOf.
+// unknown references resolve to an empty schema
+func dereferenceSchema(schemas map[string]*openapi.Schema, schema *openapi.Schema) *openapi.Schema {
+	if schema.Ref == "" && len(schema.AllOf) == 1 {
+		schema = schema.AllOf[0]
+	}
+	if schema.Ref == "" {
+		return schema
+	}
+	if target, ok := schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]; ok {
+		return target
+	}
+	return &openapi.Schema{}
+}
+
+// calls fn for every inline response of paths, webhooks, callbacks and components
+func forEachResponse(doc models.DocunyanYAML, fn func(owner string, resp models.Response) error) error {
+	var visit func(prefix string, items map[string]models.PathItem) error
+	visit = func(prefix string, items map[string]models.PathItem) error {
+		for _, key := range utils.SortedKeys(items) {
+			for _, method := range utils.SortedKeys(items[key].Operations) {
+				endpoint := items[key].Operations[method]
+				owner := strings.TrimSpace(prefix + " " + strings.ToUpper(method) + " " + key)
+				for _, code := range utils.SortedKeys(endpoint.Responses) {
+					if err := fn(owner+" response "+code, endpoint.Responses[code]); err != nil {
+						return err
+					}
+				}
+				for _, name := range utils.SortedKeys(endpoint.Callbacks) {
+					if err := visit(owner+" callback "+name+":", endpoint.Callbacks[name]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := visit("", doc.Paths); err != nil {
+		return err
+	}
+	if err := visit("webhook", doc.Webhooks); err != nil {
+		return err
+	}
+
+	if doc.Components != nil {
+		for _, name := range utils.SortedKeys(doc.Components.Responses) {
+			if err := fn("component response "+name, doc.Components.Responses[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
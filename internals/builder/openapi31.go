@@ -0,0 +1,44 @@
+package builder
+
+import (
+	"github.com/fanchann/docunyan/internals/openapi"
+)
+
+// rewrites an OpenAPI 3.0 schema into its JSON Schema 2020-12 form used by OpenAPI 3.1:
+// nullable becomes a "null" type, example becomes examples and single value enums become const
+func convertSchema31(schema *openapi.Schema) {
+	if schema == nil {
+		return
+	}
+
+	if schema.Nullable {
+		schema.Nullable = false
+		if len(schema.Type) > 0 {
+			schema.Type = append(schema.Type, "null")
+		}
+	}
+
+	if schema.Example != nil {
+		schema.Examples = []interface{}{schema.Example}
+		schema.Example = nil
+	}
+
+	if len(schema.Enum) == 1 {
+		schema.Const = schema.Enum[0]
+		schema.Enum = nil
+	}
+
+	if schema.Properties != nil {
+		for _, name := range schema.Properties.Names() {
+			property, _ := schema.Properties.Get(name)
+			convertSchema31(property)
+		}
+	}
+	convertSchema31(schema.Items)
+	convertSchema31(schema.AdditionalProperties)
+	for _, group := range [][]*openapi.Schema{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, item := range group {
+			convertSchema31(item)
+		}
+	}
+}
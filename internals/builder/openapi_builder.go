@@ -2,289 +2,617 @@ package builder
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"sort"
 	"strings"
 
 	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/openapi"
 	"github.com/fanchann/docunyan/internals/utils"
 )
 
+// supported values of Options.Version
+const (
+	Swagger20 = "2.0"
+	OpenAPI30 = "3.0"
+	OpenAPI31 = "3.1"
+)
+
+// settings of the generated document
+type Options struct {
+	Version string // OpenAPI version to target, 3.0 (default), 3.1 or 2.0 (Swagger)
+}
+
 // builds the complete OpenAPI specification
-func BuildOpenAPISpec(doc models.DocunyanYAML, schemas map[string]interface{}) ([]byte, error) {
-	// JSON Swagger
-	swagger := map[string]interface{}{
-		"openapi": "3.0.0",
-		"info": map[string]interface{}{
-			"title":       doc.Info.Title,
-			"version":     doc.Info.Version,
-			"description": doc.Info.Description,
-		},
-		"paths": map[string]interface{}{},
-		"components": map[string]interface{}{
-			"schemas": schemas,
-		},
+func BuildOpenAPISpec(doc models.DocunyanYAML, schemas map[string]*openapi.Schema, opts Options) ([]byte, error) {
+	document, err := BuildDocument(doc, schemas, opts)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeDocument(document, opts)
+}
+
+// builds the typed OpenAPI document. Swagger 2.0 documents are built as OpenAPI 3.0
+// and converted by EncodeDocument
+func BuildDocument(doc models.DocunyanYAML, schemas map[string]*openapi.Schema, opts Options) (*openapi.Document, error) {
+	version, err := NormalizeVersion(opts.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == OpenAPI31 {
+		for _, schema := range schemas {
+			convertSchema31(schema)
+		}
+	}
+
+	// reusable parameters, responses, headers and request bodies
+	components, err := buildComponents(doc.Components, doc.BaseDir)
+	if err != nil {
+		log.Printf("failed to build components: %v", err)
+		return nil, err
+	}
+	for name, schema := range schemas {
+		components.Schemas[name] = schema
+	}
+
+	document := &openapi.Document{OpenAPI: "3.0.0", Components: components}
+	if version == OpenAPI31 {
+		document.OpenAPI = "3.1.0"
+	} else {
+		for _, warning := range openAPI31Warnings(doc, version) {
+			log.Printf("warning: %s", warning)
+		}
+	}
+
+	info, err := buildInfo(doc.Info, version)
+	if err != nil {
+		log.Printf("failed to build info: %v", err)
+		return nil, err
 	}
+	document.Info = info
 
 	// add servers if defined
-	if len(doc.Servers) > 0 {
-		servers := []map[string]interface{}{}
-		for _, s := range doc.Servers {
-			server := map[string]interface{}{"url": s.URL}
-			if s.Description != "" {
-				server["description"] = s.Description
-			}
-			servers = append(servers, server)
+	for _, s := range doc.Servers {
+		document.Servers = append(document.Servers, buildServer(s))
+	}
+
+	// add tag definitions in declaration order
+	if len(doc.Tags) > 0 {
+		document.Tags = buildTags(doc.Tags)
+		for _, warning := range undeclaredTagWarnings(doc) {
+			log.Printf("warning: %s", warning)
 		}
-		swagger["servers"] = servers
 	}
 
-	// security schemes to be populated if authorization is defined
-	securitySchemes := make(map[string]interface{})
-	globalSecurity := []map[string][]string{}
+	for _, group := range doc.TagGroups {
+		document.TagGroups = append(document.TagGroups, openapi.TagGroup{Name: group.Name, Tags: group.Tags})
+	}
 
-	if doc.Authorization != nil {
-		components := swagger["components"].(map[string]interface{})
+	// security schemes from securitySchemes and the legacy authorization block
+	schemes, legacyGlobal, err := resolveSecuritySchemes(doc)
+	if err != nil {
+		log.Printf("failed to build security schemes: %v", err)
+		return nil, err
+	}
 
-		// process each auth type
-		for i, authType := range doc.Authorization.Type {
-			authType = strings.ToLower(authType)
-			var scheme string
-			if i < len(doc.Authorization.Scheme) {
-				scheme = strings.ToLower(doc.Authorization.Scheme[i])
-			} else if len(doc.Authorization.Scheme) > 0 {
-				scheme = strings.ToLower(doc.Authorization.Scheme[0])
+	if len(schemes) > 0 {
+		components.SecuritySchemes = map[string]*openapi.SecurityScheme{}
+		for _, name := range utils.SortedKeys(schemes) {
+			scheme, err := buildSecurityScheme(schemes[name])
+			if err != nil {
+				log.Printf("failed to build security scheme %s: %v", name, err)
+				return nil, fmt.Errorf("security scheme %s: %w", name, err)
 			}
+			components.SecuritySchemes[name] = scheme
+		}
+	}
 
-			securityKey := strings.Replace(authType+scheme, " ", "", -1)
+	// add global security requirements - this will be overridden at endpoint level
+	globalNames := legacyGlobal
+	if len(doc.Security) > 0 {
+		globalNames = doc.Security
+	}
+	if len(globalNames) > 0 {
+		globalSecurity, err := buildSecurityRequirements(globalNames, schemes)
+		if err != nil {
+			log.Printf("failed to build global security: %v", err)
+			return nil, err
+		}
+		document.Security = globalSecurity
+	}
 
-			switch authType {
-			case "http":
-				securitySchemes[securityKey] = map[string]interface{}{
-					"type":   "http",
-					"scheme": scheme,
-				}
+	// links must point at existing operations and response fields
+	if err := validateLinks(doc, schemas); err != nil {
+		log.Printf("invalid links: %v", err)
+		return nil, err
+	}
 
-				// add global security requirement
-				globalSecurity = append(globalSecurity, map[string][]string{
-					securityKey: {},
-				})
+	document.Paths, err = buildPaths(doc, schemes)
+	if err != nil {
+		log.Printf("failed to build paths: %v", err)
+		return nil, err
+	}
 
-			case "apikey":
-				in := "header"
-				if doc.Authorization.In != "" {
-					in = strings.ToLower(doc.Authorization.In)
-				}
+	// webhooks are an OpenAPI 3.1 feature, 3.0 documents carry them as an extension
+	if len(doc.Webhooks) > 0 {
+		webhooks, err := buildWebhooks(doc, schemes)
+		if err != nil {
+			log.Printf("failed to build webhooks: %v", err)
+			return nil, err
+		}
+		if version == OpenAPI31 {
+			document.Webhooks = webhooks
+		} else {
+			document.XWebhooks = webhooks
+		}
+	}
 
-				securitySchemes[securityKey] = map[string]interface{}{
-					"type": "apiKey",
-					"name": doc.Authorization.Name,
-					"in":   in,
-				}
+	return document, nil
+}
 
-				// add global security requirement
-				globalSecurity = append(globalSecurity, map[string][]string{
-					securityKey: {},
-				})
+// encodes a document as indented JSON, down-converting it for Swagger 2.0
+func EncodeDocument(document *openapi.Document, opts Options) ([]byte, error) {
+	version, err := NormalizeVersion(opts.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec interface{} = document
+	if version == Swagger20 {
+		spec = downConvertSwagger2(document)
+	}
+
+	output, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		log.Printf("failed to marshal json: %v", err)
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// maps the accepted spellings of Options.Version to one of the version constants
+func NormalizeVersion(version string) (string, error) {
+	switch version {
+	case "", OpenAPI30, "3.0.0":
+		return OpenAPI30, nil
+	case OpenAPI31, "3.1.0":
+		return OpenAPI31, nil
+	case Swagger20, "2":
+		return Swagger20, nil
+	}
+	return "", fmt.Errorf("unsupported OpenAPI version %q, expected %s, %s or %s", version, OpenAPI30, OpenAPI31, Swagger20)
+}
+
+// builds the info object including contact, license and logo.
+// summary and license identifier only exist in OpenAPI 3.1
+func buildInfo(info models.Info, version string) (openapi.Info, error) {
+	infoObj := openapi.Info{
+		Title:          info.Title,
+		Description:    info.Description,
+		TermsOfService: info.TermsOfService,
+		Version:        info.Version,
+	}
+
+	if version == OpenAPI31 {
+		infoObj.Summary = info.Summary
+	}
 
+	if info.Contact != nil {
+		infoObj.Contact = &openapi.Contact{
+			Name:  info.Contact.Name,
+			URL:   info.Contact.URL,
+			Email: info.Contact.Email,
+		}
+	}
+
+	if info.License != nil {
+		infoObj.License = &openapi.License{Name: info.License.Name, URL: info.License.URL}
+		if info.License.Identifier != "" && version == OpenAPI31 {
+			if info.License.URL != "" {
+				return openapi.Info{}, fmt.Errorf("license identifier and url are mutually exclusive")
 			}
+			infoObj.License.Identifier = info.License.Identifier
+		}
+	}
+
+	if info.Logo != nil {
+		infoObj.Logo = &openapi.Logo{
+			URL:             info.Logo.URL,
+			AltText:         info.Logo.AltText,
+			BackgroundColor: info.Logo.BackgroundColor,
+			Href:            info.Logo.Href,
 		}
+	}
+
+	return infoObj, nil
+}
 
-		if len(securitySchemes) > 0 {
-			components["securitySchemes"] = securitySchemes
-			// add global security requirements - this will be overridden at endpoint level
-			if len(globalSecurity) > 0 {
-				swagger["security"] = globalSecurity
+// builds a server object with its URL template variables
+func buildServer(s models.Server) openapi.Server {
+	server := openapi.Server{URL: s.URL, Description: s.Description}
+
+	if len(s.Variables) > 0 {
+		server.Variables = map[string]openapi.ServerVariable{}
+		for name, variable := range s.Variables {
+			server.Variables[name] = openapi.ServerVariable{
+				Enum:        variable.Enum,
+				Default:     variable.Default,
+				Description: variable.Description,
 			}
 		}
 	}
 
-	paths := buildPaths(doc.Paths, securitySchemes)
-	swagger["paths"] = paths
+	return server
+}
+
+// builds the paths section of the OpenAPI spec
+func buildPaths(doc models.DocunyanYAML, schemes map[string]models.SecurityScheme) (map[string]*openapi.PathItem, error) {
+	paths := map[string]*openapi.PathItem{}
 
-	output, err := json.MarshalIndent(swagger, "", "  ")
+	operationIDs, renamed, err := AssignOperationIDs(doc)
 	if err != nil {
-		log.Printf("failed to marshal json: %v", err)
 		return nil, err
 	}
+	for _, rename := range renamed {
+		log.Printf("warning: %s", rename)
+	}
 
-	return output, nil
+	for _, path := range utils.SortedKeys(doc.Paths) {
+		pathItem, err := buildPathItem(doc, path, doc.Paths[path], schemes, operationIDs[path], false)
+		if err != nil {
+			return nil, err
+		}
+
+		openAPIPath := utils.NormalizePathParams(path)
+		paths[openAPIPath] = pathItem
+	}
+
+	return paths, nil
 }
 
-// builds the paths section of the OpenAPI spec
-func buildPaths(docPaths map[string]map[string]models.EndpointDetail, securitySchemes map[string]interface{}) map[string]interface{} {
-	paths := map[string]interface{}{}
-
-	for path, endpoints := range docPaths {
-		pathItem := map[string]interface{}{}
-
-		for method, endpoint := range endpoints {
-			methodLower := strings.ToLower(method)
-
-			// responses object
-			responseObj := map[string]interface{}{}
-			for code, resp := range endpoint.Responses {
-				responseObj[code] = map[string]interface{}{
-					"description": resp.Description,
-					"content": map[string]interface{}{
-						"application/json": map[string]interface{}{
-							"schema": map[string]interface{}{
-								"$ref": "#/components/schemas/" + resp.Schema,
-							},
-						},
-					},
+// builds the operations of a single path. outgoing path items (webhooks and callbacks)
+// only carry explicit operationIds and security
+func buildPathItem(doc models.DocunyanYAML, path string, item models.PathItem, schemes map[string]models.SecurityScheme, operationIDs map[string]string, outgoing bool) (*openapi.PathItem, error) {
+	pathItem := &openapi.PathItem{}
+
+	// parameters declared once for every method of this path
+	for _, param := range item.Parameters {
+		pathItem.Parameters = append(pathItem.Parameters, buildParameter(param))
+	}
+
+	for _, method := range utils.SortedMethods(item.Operations) {
+		endpoint := item.Operations[method]
+
+		operation := &openapi.Operation{
+			Tags:        endpoint.Tags,
+			Summary:     endpoint.Summary,
+			Description: endpoint.Description,
+			OperationID: endpoint.OperationID,
+			Responses:   map[string]*openapi.Response{},
+			Deprecated:  endpoint.Deprecated,
+		}
+
+		if operationID := operationIDs[method]; operationID != "" {
+			operation.OperationID = operationID
+		}
+
+		if endpoint.ExternalDocs != nil {
+			operation.ExternalDocs = buildExternalDocs(endpoint.ExternalDocs)
+		}
+
+		for _, code := range utils.SortedKeys(endpoint.Responses) {
+			response, err := buildResponse(endpoint.Responses[code], doc.BaseDir)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s response %s: %w", strings.ToUpper(method), path, code, err)
+			}
+			operation.Responses[code] = response
+		}
+
+		// Handle endpoint-specific authorization
+		switch {
+		case endpoint.Security != nil:
+			// explicitly selected schemes, an empty list makes the endpoint public
+			security, err := buildSecurityRequirements(endpoint.Security, schemes)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			operation.Security = &security
+		case endpoint.Authorization:
+			// add all available security schemes to this endpoint
+			if len(schemes) > 0 {
+				security, err := buildSecurityRequirements(allSchemeRequirements(schemes), schemes)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
 				}
+				operation.Security = &security
 			}
+		case len(doc.Security) > 0 || outgoing:
+			// inherit the global security requirements, outgoing requests are not
+			// secured by the API's own schemes
+		default:
+			// if authorization is false (default), explicitly override global security
+			// by providing an empty security requirement object
+			operation.Security = &[]openapi.SecurityRequirement{{}}
+		}
 
-			methodObj := map[string]interface{}{
-				"summary":   endpoint.Summary,
-				"responses": responseObj,
+		// Handle request body if specified
+		if body := endpoint.RequestBody; body != nil && (body.Schema != "" || body.Ref != "") {
+			requestBody, err := buildRequestBody(*body, doc.BaseDir)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s request body: %w", strings.ToUpper(method), path, err)
 			}
+			operation.RequestBody = requestBody
+		}
 
-			// add tags if present
-			if len(endpoint.Tags) > 0 {
-				methodObj["tags"] = endpoint.Tags
+		// path parameters (extracted from path), unless declared explicitly
+		declared := declaredPathParams(doc.Components, item.Parameters, endpoint.Parameters)
+		for _, param := range utils.ExtractPathParams(path) {
+			if declared[param] {
+				continue
 			}
+			operation.Parameters = append(operation.Parameters, &openapi.Parameter{
+				Name:     param,
+				In:       "path",
+				Required: true,
+				Schema:   &openapi.Schema{Type: openapi.SchemaType{"string"}},
+			})
+		}
 
-			// Handle endpoint-specific authorization
-			if endpoint.Authorization {
-				// add security requirement to this endpoint
-				if securitySchemes != nil && len(securitySchemes) > 0 {
-					security := []map[string][]string{}
-
-					// add all available security schemes to this endpoint
-					for schemeName := range securitySchemes {
-						security = append(security, map[string][]string{
-							schemeName: {},
-						})
-					}
-
-					if len(security) > 0 {
-						methodObj["security"] = security
-					}
-				}
+		// handle query parameters from the new query field
+		for _, query := range endpoint.Query {
+			operation.Parameters = append(operation.Parameters, &openapi.Parameter{
+				Name:   query.Name,
+				In:     "query",
+				Schema: &openapi.Schema{Type: openapi.SchemaType{utils.GoTypeToSwaggerType(query.Type)}},
+			})
+		}
+
+		if endpoint.Parameter != nil {
+			param, err := buildLegacyParameter(endpoint.Parameter)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s parameter: %w", strings.ToUpper(method), path, err)
+			}
+			if param != nil {
+				operation.Parameters = append(operation.Parameters, param)
+			}
+		}
+
+		// add explicitly defined parameters
+		for _, param := range endpoint.Parameters {
+			operation.Parameters = append(operation.Parameters, buildParameter(param))
+		}
+
+		// outgoing requests the API makes once this operation has run
+		if len(endpoint.Callbacks) > 0 {
+			callbacks, err := buildCallbacks(doc, endpoint.Callbacks, schemes)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			operation.Callbacks = callbacks
+		}
+
+		if !pathItem.SetOperation(strings.ToLower(method), operation) {
+			return nil, fmt.Errorf("%s %s: unsupported HTTP method", strings.ToUpper(method), path)
+		}
+	}
+
+	return pathItem, nil
+}
+
+// builds the parameter of the legacy parameter field, either a type name or a
+// mapping with name, in, required and schema
+func buildLegacyParameter(value interface{}) (*openapi.Parameter, error) {
+	switch p := utils.ConvertYAMLValue(value).(type) {
+	case string:
+		return &openapi.Parameter{
+			Name:     "body",
+			In:       "query", // Default to query
+			Required: true,
+			Schema:   &openapi.Schema{Type: openapi.SchemaType{utils.GoTypeToSwaggerType(p)}},
+		}, nil
+	case map[string]interface{}:
+		name, ok := p["name"].(string)
+		if !ok {
+			// parameters without a name were always skipped
+			return nil, nil
+		}
+
+		param := &openapi.Parameter{Name: name, In: "query"}
+		if in, ok := p["in"]; ok {
+			if param.In, ok = in.(string); !ok {
+				return nil, fmt.Errorf("in of %s must be a string", name)
+			}
+		}
+		if required, ok := p["required"]; ok {
+			if param.Required, ok = required.(bool); !ok {
+				return nil, fmt.Errorf("required of %s must be a boolean", name)
+			}
+		}
+		if schema, ok := p["schema"].(string); ok {
+			if _, exists := models.StructSchemas[schema]; exists {
+				param.Schema = &openapi.Schema{Ref: "#/components/schemas/" + schema}
 			} else {
-				// if authorization is false (default), explicitly override global security
-				// by providing an empty security requirement object
-				methodObj["security"] = []map[string][]string{{}}
+				param.Schema = &openapi.Schema{Type: openapi.SchemaType{utils.GoTypeToSwaggerType(schema)}}
 			}
+		}
+		return param, nil
+	}
+	return nil, fmt.Errorf("must be a type name or a mapping")
+}
 
-			// Handle request body if specified
-			if endpoint.RequestBody != "" {
-				methodObj["requestBody"] = map[string]interface{}{
-					"required": true,
-					"content": map[string]interface{}{
-						"application/json": map[string]interface{}{
-							"schema": map[string]interface{}{
-								"$ref": "#/components/schemas/" + endpoint.RequestBody,
-							},
-						},
-					},
-				}
+// collects the names of path parameters declared at path or operation level
+func declaredPathParams(components *models.Components, groups ...[]models.Parameter) map[string]bool {
+	declared := map[string]bool{}
+	for _, params := range groups {
+		for _, param := range params {
+			if param.Ref != "" && components != nil {
+				param = components.Parameters[param.Ref]
+			}
+			if strings.ToLower(param.In) == "path" {
+				declared[param.Name] = true
 			}
+		}
+	}
+	return declared
+}
 
-			// Handle parameters
-			params := []map[string]interface{}{}
-
-			// path parameters (extracted from path)
-			pathParams := utils.ExtractPathParams(path)
-			for _, param := range pathParams {
-				params = append(params, map[string]interface{}{
-					"name":     param,
-					"in":       "path",
-					"required": true,
-					"schema":   map[string]interface{}{"type": "string"},
-				})
+// a generated operationId that was already taken and got a numeric suffix instead
+type RenamedOperationID struct {
+	Path        string
+	Method      string
+	Generated   string // derived from method + path
+	OperationID string // the unique id used in the spec
+	TakenBy     string // operation that owns the generated id
+}
+
+func (r RenamedOperationID) String() string {
+	return fmt.Sprintf("%s %s: generated operationId %q is already used by %s, using %q",
+		strings.ToUpper(r.Method), r.Path, r.Generated, r.TakenBy, r.OperationID)
+}
+
+// resolves the operationId of every endpoint, generating missing ones from method + path.
+// explicit duplicates are an error, generated duplicates get a numeric suffix and are
+// reported as renamed. webhooks and callbacks take part with their explicit operationIds only
+func AssignOperationIDs(doc models.DocunyanYAML) (map[string]map[string]string, []RenamedOperationID, error) {
+	docPaths := doc.Paths
+	operationIDs := map[string]map[string]string{}
+	owners := map[string]string{}
+	renamed := []RenamedOperationID{}
+
+	for _, op := range outgoingOperations(doc) {
+		if other, exists := owners[op.operationID]; exists {
+			return nil, nil, fmt.Errorf("duplicate operationId %q used by %s and %s", op.operationID, other, op.owner)
+		}
+		owners[op.operationID] = op.owner
+	}
+
+	// sorted iteration keeps generated suffixes stable between runs
+	paths := utils.SortedKeys(docPaths)
+	methodsOf := func(path string) []string {
+		return utils.SortedKeys(docPaths[path].Operations)
+	}
+
+	// explicit operationIds claim their names first
+	for _, path := range paths {
+		operationIDs[path] = map[string]string{}
+		for _, method := range methodsOf(path) {
+			operationID := docPaths[path].Operations[method].OperationID
+			if operationID == "" {
+				continue
 			}
 
-			// handle query parameters from the new query field
-			if endpoint.Query != nil {
-				for paramName, paramType := range endpoint.Query {
-					paramObj := map[string]interface{}{
-						"name":     paramName,
-						"in":       "query",
-						"required": false,
-						"schema": map[string]interface{}{
-							"type": utils.GoTypeToSwaggerType(paramType),
-						},
-					}
-					params = append(params, paramObj)
-				}
+			owner := strings.ToUpper(method) + " " + path
+			if other, exists := owners[operationID]; exists {
+				return nil, nil, fmt.Errorf("duplicate operationId %q used by %s and %s", operationID, other, owner)
 			}
+			owners[operationID] = owner
+			operationIDs[path][method] = operationID
+		}
+	}
 
-			if endpoint.Parameter != nil {
-				switch p := endpoint.Parameter.(type) {
-				case string:
-					// Simple type parameter
-					params = append(params, map[string]interface{}{
-						"name":     "body",
-						"in":       "query", // Default to query
-						"required": true,
-						"schema":   map[string]interface{}{"type": utils.GoTypeToSwaggerType(p)},
-					})
-				case map[interface{}]interface{}:
-					// Complex parameter object
-					paramMap := utils.ConvertToStringMap(p)
-					if name, ok := paramMap["name"].(string); ok {
-						paramObj := map[string]interface{}{
-							"name":     name,
-							"in":       paramMap["in"].(string),
-							"required": paramMap["required"].(bool),
-						}
-						if schema, ok := paramMap["schema"].(string); ok {
-							if _, exists := models.StructSchemas[schema]; exists {
-								paramObj["schema"] = map[string]interface{}{
-									"$ref": "#/components/schemas/" + schema,
-								}
-							} else {
-								paramObj["schema"] = map[string]interface{}{
-									"type": utils.GoTypeToSwaggerType(schema),
-								}
-							}
-						}
-						params = append(params, paramObj)
-					}
-				}
+	for _, path := range paths {
+		for _, method := range methodsOf(path) {
+			if operationIDs[path][method] != "" {
+				continue
 			}
 
-			// add explicitly defined parameters
-			for _, param := range endpoint.Parameters {
-				paramObj := map[string]interface{}{
-					"name":     param.Name,
-					"in":       param.In,
-					"required": param.Required,
-				}
+			base := utils.GenerateOperationID(method, path)
+			operationID := base
+			for i := 2; owners[operationID] != ""; i++ {
+				operationID = fmt.Sprintf("%s%d", base, i)
+			}
+			if operationID != base {
+				renamed = append(renamed, RenamedOperationID{
+					Path: path, Method: method, Generated: base, OperationID: operationID, TakenBy: owners[base],
+				})
+			}
+			owners[operationID] = strings.ToUpper(method) + " " + path
+			operationIDs[path][method] = operationID
+		}
+	}
 
-				if param.Description != "" {
-					paramObj["description"] = param.Description
-				}
+	return operationIDs, renamed, nil
+}
 
-				schemaObj := map[string]interface{}{
-					"type": param.Type,
-				}
+func buildExternalDocs(docs *models.ExternalDocs) *openapi.ExternalDocs {
+	return &openapi.ExternalDocs{Description: docs.Description, URL: docs.URL}
+}
 
-				// add format based on type
-				switch param.Type {
-				case "integer":
-					schemaObj["format"] = "int64"
-				case "number":
-					schemaObj["format"] = "double"
-				}
+func buildTags(tags []models.Tag) []openapi.Tag {
+	result := []openapi.Tag{}
+	for _, tag := range tags {
+		tagObj := openapi.Tag{Name: tag.Name, Description: tag.Description}
+		if tag.ExternalDocs != nil {
+			tagObj.ExternalDocs = buildExternalDocs(tag.ExternalDocs)
+		}
+		result = append(result, tagObj)
+	}
+	return result
+}
 
-				paramObj["schema"] = schemaObj
-				params = append(params, paramObj)
-			}
+// reports parts of the config that are dropped from an OpenAPI 3.0 (or Swagger 2.0) document
+func openAPI31Warnings(doc models.DocunyanYAML, version string) []string {
+	warnings := []string{}
+	if doc.Info.Summary != "" {
+		warnings = append(warnings, "info.summary requires OpenAPI 3.1 and is omitted")
+	}
+	if doc.Info.License != nil && doc.Info.License.Identifier != "" {
+		warnings = append(warnings, "info.license.identifier requires OpenAPI 3.1 and is omitted")
+	}
+	if version == Swagger20 {
+		// the down-conversion reports the rest
+		return warnings
+	}
+	for _, name := range utils.SortedKeys(doc.SecuritySchemes) {
+		if strings.ToLower(doc.SecuritySchemes[name].Type) == "mutualtls" {
+			warnings = append(warnings, fmt.Sprintf("security scheme %s: mutualTLS requires OpenAPI 3.1", name))
+		}
+	}
+	if len(doc.Webhooks) > 0 {
+		warnings = append(warnings, "webhooks are emitted as x-webhooks, use OpenAPI 3.1 for native webhooks")
+	}
+	return warnings
+}
 
-			// add parameters if we have any
-			if len(params) > 0 {
-				methodObj["parameters"] = params
+// reports tags used by endpoints or tag groups that are missing from the tags list
+func undeclaredTagWarnings(doc models.DocunyanYAML) []string {
+	declared := map[string]bool{}
+	for _, tag := range doc.Tags {
+		declared[tag.Name] = true
+	}
+
+	warnings := []string{}
+	for path, item := range doc.Paths {
+		for method, endpoint := range item.Operations {
+			for _, tag := range endpoint.Tags {
+				if !declared[tag] {
+					warnings = append(warnings, fmt.Sprintf("%s %s uses undeclared tag %q", strings.ToUpper(method), path, tag))
+				}
 			}
+		}
+	}
 
-			pathItem[methodLower] = methodObj
+	for name, item := range doc.Webhooks {
+		for method, endpoint := range item.Operations {
+			for _, tag := range endpoint.Tags {
+				if !declared[tag] {
+					warnings = append(warnings, fmt.Sprintf("webhook %s %s uses undeclared tag %q", strings.ToUpper(method), name, tag))
+				}
+			}
 		}
+	}
 
-		openAPIPath := utils.NormalizePathParams(path)
-		paths[openAPIPath] = pathItem
+	for _, group := range doc.TagGroups {
+		for _, tag := range group.Tags {
+			if !declared[tag] {
+				warnings = append(warnings, fmt.Sprintf("tag group %q uses undeclared tag %q", group.Name, tag))
+			}
+		}
 	}
 
-	return paths
+	sort.Strings(warnings)
+	return warnings
 }
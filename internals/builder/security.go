@@ -0,0 +1,240 @@
+package builder
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/openapi"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// collects the named security schemes together with the ones converted from the
+// legacy authorization block, and the names required globally by the legacy block
+func resolveSecuritySchemes(doc models.DocunyanYAML) (map[string]models.SecurityScheme, []models.SecurityRequirement, error) {
+	schemes := map[string]models.SecurityScheme{}
+	legacyGlobal := []models.SecurityRequirement{}
+
+	if doc.Authorization != nil {
+		// process each auth type
+		for i, authType := range doc.Authorization.Type {
+			authType = strings.ToLower(authType)
+			securityKey, scheme := LegacySecurityKey(*doc.Authorization, i)
+
+			switch authType {
+			case "http":
+				schemes[securityKey] = models.SecurityScheme{
+					Type:   "http",
+					Scheme: scheme,
+				}
+			case "apikey":
+				in := "header"
+				if doc.Authorization.In != "" {
+					in = strings.ToLower(doc.Authorization.In)
+				}
+
+				schemes[securityKey] = models.SecurityScheme{
+					Type: "apiKey",
+					Name: doc.Authorization.Name,
+					In:   in,
+				}
+			default:
+				log.Printf("warning: authorization type %q is not supported by the legacy authorization block, declare it under securitySchemes", authType)
+				continue
+			}
+
+			legacyGlobal = append(legacyGlobal, models.SecurityRequirement{{Name: securityKey}})
+		}
+	}
+
+	for _, name := range utils.SortedKeys(doc.SecuritySchemes) {
+		if _, exists := schemes[name]; exists {
+			return nil, nil, fmt.Errorf("security scheme %q is declared by both authorization and securitySchemes", name)
+		}
+		schemes[name] = doc.SecuritySchemes[name]
+	}
+
+	return schemes, legacyGlobal, nil
+}
+
+// the name the legacy authorization block declares its i-th type under, and the
+// scheme it pairs with. types past the listed schemes reuse the first scheme
+func LegacySecurityKey(authorization models.Authorization, i int) (string, string) {
+	var scheme string
+	if i < len(authorization.Scheme) {
+		scheme = strings.ToLower(authorization.Scheme[i])
+	} else if len(authorization.Scheme) > 0 {
+		scheme = strings.ToLower(authorization.Scheme[0])
+	}
+	return strings.Replace(strings.ToLower(authorization.Type[i])+scheme, " ", "", -1), scheme
+}
+
+// builds a single security scheme object
+func buildSecurityScheme(scheme models.SecurityScheme) (*openapi.SecurityScheme, error) {
+	var schemeObj *openapi.SecurityScheme
+
+	switch strings.ToLower(scheme.Type) {
+	case "http":
+		if scheme.Scheme == "" {
+			return nil, fmt.Errorf("http security scheme requires scheme")
+		}
+		schemeObj = &openapi.SecurityScheme{
+			Type:   "http",
+			Scheme: strings.ToLower(scheme.Scheme),
+		}
+		if scheme.BearerFormat != "" {
+			if strings.ToLower(scheme.Scheme) != "bearer" {
+				return nil, fmt.Errorf("bearerFormat is only valid for the bearer scheme")
+			}
+			schemeObj.BearerFormat = scheme.BearerFormat
+		}
+	case "apikey":
+		if scheme.Name == "" {
+			return nil, fmt.Errorf("apiKey security scheme requires name")
+		}
+		in := "header"
+		if scheme.In != "" {
+			in = strings.ToLower(scheme.In)
+		}
+		if in != "header" && in != "query" && in != "cookie" {
+			return nil, fmt.Errorf("invalid apiKey location %q, expected header, query or cookie", scheme.In)
+		}
+		schemeObj = &openapi.SecurityScheme{
+			Type: "apiKey",
+			Name: scheme.Name,
+			In:   in,
+		}
+	case "oauth2":
+		flows, err := BuildOAuthFlows(scheme.Flows)
+		if err != nil {
+			return nil, err
+		}
+		schemeObj = &openapi.SecurityScheme{
+			Type:  "oauth2",
+			Flows: flows,
+		}
+	case "openidconnect":
+		if scheme.OpenIDConnectURL == "" {
+			return nil, fmt.Errorf("openIdConnect security scheme requires openIdConnectUrl")
+		}
+		schemeObj = &openapi.SecurityScheme{
+			Type:             "openIdConnect",
+			OpenIDConnectURL: scheme.OpenIDConnectURL,
+		}
+	case "mutualtls":
+		schemeObj = &openapi.SecurityScheme{Type: "mutualTLS"}
+	default:
+		return nil, fmt.Errorf("unsupported security scheme type %q", scheme.Type)
+	}
+
+	schemeObj.Description = scheme.Description
+	return schemeObj, nil
+}
+
+// a flow without one of the URLs it needs, Key is authorizationUrl or tokenUrl
+type MissingFlowURLError struct {
+	Flow string
+	Key  string
+}
+
+func (e *MissingFlowURLError) Error() string {
+	return fmt.Sprintf("oauth2 %s flow requires %s", e.Flow, e.Key)
+}
+
+// builds the flows object of an oauth2 scheme, checking the URLs each flow needs
+func BuildOAuthFlows(flows *models.OAuthFlows) (*openapi.OAuthFlows, error) {
+	if flows == nil {
+		return nil, fmt.Errorf("oauth2 security scheme requires flows")
+	}
+
+	result := &openapi.OAuthFlows{}
+	build := func(name string, flow *models.OAuthFlow, needsAuthorizationURL, needsTokenURL bool) (*openapi.OAuthFlow, error) {
+		if flow == nil {
+			return nil, nil
+		}
+		if needsAuthorizationURL && flow.AuthorizationURL == "" {
+			return nil, &MissingFlowURLError{Flow: name, Key: "authorizationUrl"}
+		}
+		if needsTokenURL && flow.TokenURL == "" {
+			return nil, &MissingFlowURLError{Flow: name, Key: "tokenUrl"}
+		}
+
+		scopes := flow.Scopes
+		if scopes == nil {
+			scopes = map[string]string{}
+		}
+		flowObj := &openapi.OAuthFlow{
+			RefreshURL: flow.RefreshURL,
+			Scopes:     scopes,
+		}
+		if needsAuthorizationURL {
+			flowObj.AuthorizationURL = flow.AuthorizationURL
+		}
+		if needsTokenURL {
+			flowObj.TokenURL = flow.TokenURL
+		}
+		return flowObj, nil
+	}
+
+	var err error
+	if result.AuthorizationCode, err = build("authorizationCode", flows.AuthorizationCode, true, true); err != nil {
+		return nil, err
+	}
+	if result.ClientCredentials, err = build("clientCredentials", flows.ClientCredentials, false, true); err != nil {
+		return nil, err
+	}
+	if result.Password, err = build("password", flows.Password, false, true); err != nil {
+		return nil, err
+	}
+	if result.Implicit, err = build("implicit", flows.Implicit, true, false); err != nil {
+		return nil, err
+	}
+
+	if *result == (openapi.OAuthFlows{}) {
+		return nil, fmt.Errorf("oauth2 security scheme requires at least one flow")
+	}
+	return result, nil
+}
+
+// builds security requirements where any one of the listed alternatives is sufficient,
+// all schemes inside an alternative are required together
+func buildSecurityRequirements(requirements []models.SecurityRequirement, schemes map[string]models.SecurityScheme) ([]openapi.SecurityRequirement, error) {
+	security := []openapi.SecurityRequirement{}
+	for _, requirement := range requirements {
+		// an empty requirement object allows anonymous access
+		requirementObj := openapi.SecurityRequirement{}
+
+		for _, required := range requirement {
+			scheme, ok := schemes[required.Name]
+			if !ok {
+				return nil, fmt.Errorf("unknown security scheme %q", required.Name)
+			}
+			if _, duplicate := requirementObj[required.Name]; duplicate {
+				return nil, fmt.Errorf("security scheme %q is listed twice in one requirement", required.Name)
+			}
+
+			// scopes of oauth2 schemes must be declared by one of its flows
+			scopes := []string{}
+			for _, scope := range required.Scopes {
+				if strings.ToLower(scheme.Type) == "oauth2" && !scheme.HasScope(scope) {
+					return nil, fmt.Errorf("scope %q is not declared by security scheme %q", scope, required.Name)
+				}
+				scopes = append(scopes, scope)
+			}
+			requirementObj[required.Name] = scopes
+		}
+
+		security = append(security, requirementObj)
+	}
+	return security, nil
+}
+
+// requirements for every security scheme in a stable order
+func allSchemeRequirements(schemes map[string]models.SecurityScheme) []models.SecurityRequirement {
+	requirements := []models.SecurityRequirement{}
+	for _, name := range utils.SortedKeys(schemes) {
+		requirements = append(requirements, models.SecurityRequirement{{Name: name}})
+	}
+	return requirements
+}
@@ -0,0 +1,660 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/openapi"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// references of an OpenAPI 3.0 document and their Swagger 2.0 counterparts
+var swagger2Refs = []struct{ from, to string }{
+	{"#/components/schemas/", "#/definitions/"},
+	{"#/components/parameters/", "#/parameters/"},
+	{"#/components/requestBodies/", "#/parameters/"},
+	{"#/components/responses/", "#/responses/"},
+}
+
+// Swagger 2.0 document, field order follows the order of the specification
+type swagger2Document struct {
+	Swagger             string                             `json:"swagger"`
+	Info                openapi.Info                       `json:"info"`
+	Host                string                             `json:"host,omitempty"`
+	BasePath            string                             `json:"basePath,omitempty"`
+	Schemes             []string                           `json:"schemes,omitempty"`
+	Consumes            []string                           `json:"consumes"`
+	Produces            []string                           `json:"produces"`
+	Tags                []openapi.Tag                      `json:"tags,omitempty"`
+	TagGroups           []openapi.TagGroup                 `json:"x-tagGroups,omitempty"`
+	Security            *[]openapi.SecurityRequirement     `json:"security,omitempty"`
+	Paths               map[string]*swagger2PathItem       `json:"paths"`
+	Definitions         map[string]*openapi.Schema         `json:"definitions,omitempty"`
+	Parameters          map[string]*swagger2Parameter      `json:"parameters,omitempty"`
+	Responses           map[string]*swagger2Response       `json:"responses,omitempty"`
+	SecurityDefinitions map[string]*swagger2SecurityScheme `json:"securityDefinitions,omitempty"`
+}
+
+type swagger2PathItem struct {
+	Parameters []*swagger2Parameter `json:"parameters,omitempty"`
+	Get        *swagger2Operation   `json:"get,omitempty"`
+	Put        *swagger2Operation   `json:"put,omitempty"`
+	Post       *swagger2Operation   `json:"post,omitempty"`
+	Delete     *swagger2Operation   `json:"delete,omitempty"`
+	Options    *swagger2Operation   `json:"options,omitempty"`
+	Head       *swagger2Operation   `json:"head,omitempty"`
+	Patch      *swagger2Operation   `json:"patch,omitempty"`
+	Trace      *swagger2Operation   `json:"trace,omitempty"`
+}
+
+type swagger2Operation struct {
+	Tags         []string                       `json:"tags,omitempty"`
+	Summary      string                         `json:"summary"`
+	Description  string                         `json:"description,omitempty"`
+	ExternalDocs *openapi.ExternalDocs          `json:"externalDocs,omitempty"`
+	OperationID  string                         `json:"operationId,omitempty"`
+	Parameters   []*swagger2Parameter           `json:"parameters,omitempty"`
+	Responses    map[string]*swagger2Response   `json:"responses"`
+	Deprecated   bool                           `json:"deprecated,omitempty"`
+	Security     *[]openapi.SecurityRequirement `json:"security,omitempty"`
+}
+
+// keywords of a primitive schema, flattened into non-body parameters and headers
+type swagger2Items struct {
+	Type    string         `json:"type,omitempty"`
+	Format  string         `json:"format,omitempty"`
+	Items   *swagger2Items `json:"items,omitempty"`
+	Enum    []interface{}  `json:"enum,omitempty"`
+	Default interface{}    `json:"default,omitempty"`
+}
+
+// parameter, or a reference to one when Ref is set. body parameters carry a
+// schema, the others the keywords of a primitive schema
+type swagger2Parameter struct {
+	Ref         string          `json:"-"`
+	Name        string          `json:"name"`
+	In          string          `json:"in"`
+	Description string          `json:"description,omitempty"`
+	Required    bool            `json:"required"`
+	Schema      *openapi.Schema `json:"schema,omitempty"`
+	swagger2Items
+}
+
+func (p swagger2Parameter) MarshalJSON() ([]byte, error) {
+	if p.Ref != "" {
+		return json.Marshal(openapi.Reference{Ref: p.Ref})
+	}
+	type parameter swagger2Parameter
+	return json.Marshal(parameter(p))
+}
+
+// response, or a reference to one when Ref is set
+type swagger2Response struct {
+	Ref         string                     `json:"-"`
+	Description string                     `json:"description"`
+	Schema      *openapi.Schema            `json:"schema,omitempty"`
+	Examples    map[string]interface{}     `json:"examples,omitempty"`
+	Headers     map[string]*swagger2Header `json:"headers,omitempty"`
+}
+
+func (r swagger2Response) MarshalJSON() ([]byte, error) {
+	if r.Ref != "" {
+		return json.Marshal(openapi.Reference{Ref: r.Ref})
+	}
+	type response swagger2Response
+	return json.Marshal(response(r))
+}
+
+type swagger2Header struct {
+	Description string `json:"description,omitempty"`
+	swagger2Items
+}
+
+type swagger2SecurityScheme struct {
+	Type             string            `json:"type"`
+	Name             string            `json:"name,omitempty"`
+	In               string            `json:"in,omitempty"`
+	Flow             string            `json:"flow,omitempty"`
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes,omitempty"`
+	Description      string            `json:"description,omitempty"`
+}
+
+// down-converts an OpenAPI 3.0 document into Swagger 2.0. constructs without a 2.0
+// equivalent are dropped and reported as warnings
+type swagger2Converter struct {
+	document            *openapi.Document
+	securityDefinitions map[string]*swagger2SecurityScheme
+	warnings            map[string]bool
+}
+
+// converts the document, logging what was lost
+func downConvertSwagger2(document *openapi.Document) *swagger2Document {
+	result, warnings := convertToSwagger2(document)
+	for _, warning := range warnings {
+		log.Printf("warning: %s", warning)
+	}
+	return result
+}
+
+func convertToSwagger2(document *openapi.Document) (*swagger2Document, []string) {
+	c := &swagger2Converter{
+		document:            document,
+		securityDefinitions: map[string]*swagger2SecurityScheme{},
+		warnings:            map[string]bool{},
+	}
+
+	result := &swagger2Document{
+		Swagger:   "2.0",
+		Info:      document.Info,
+		Consumes:  []string{"application/json"},
+		Produces:  []string{"application/json"},
+		Tags:      document.Tags,
+		TagGroups: document.TagGroups,
+		Paths:     map[string]*swagger2PathItem{},
+	}
+	c.convertServers(result)
+
+	components := document.Components
+	if components == nil {
+		components = &openapi.Components{}
+	}
+	dropped := c.convertSecuritySchemes(components.SecuritySchemes)
+
+	if len(document.Security) > 0 {
+		if security := c.convertSecurity(document.Security, dropped, "global security"); len(security) > 0 {
+			result.Security = &security
+		} else {
+			c.warn("global security: no requirement is supported, operations are described without security")
+		}
+	}
+
+	for path, item := range document.Paths {
+		result.Paths[path] = c.convertPathItem(path, item, dropped)
+	}
+
+	if len(document.XWebhooks) > 0 || len(document.Webhooks) > 0 {
+		c.warn("webhooks are not supported and were dropped")
+	}
+
+	c.convertComponents(result, components)
+
+	return result, c.sortedWarnings()
+}
+
+func (c *swagger2Converter) warn(format string, args ...interface{}) {
+	c.warnings["swagger 2.0: "+fmt.Sprintf(format, args...)] = true
+}
+
+func (c *swagger2Converter) sortedWarnings() []string {
+	warnings := make([]string, 0, len(c.warnings))
+	for warning := range c.warnings {
+		warnings = append(warnings, warning)
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// derives host, basePath and schemes from the servers, using variable defaults
+func (c *swagger2Converter) convertServers(result *swagger2Document) {
+	var host, basePath string
+	for i, server := range c.document.Servers {
+		parsed, err := url.Parse(serverURL(server))
+		if err != nil {
+			c.warn("server %q is not a valid URL", server.URL)
+			continue
+		}
+
+		if i == 0 {
+			host, basePath = parsed.Host, parsed.Path
+		} else if parsed.Host != host || parsed.Path != basePath {
+			c.warn("only the first server is used, %s was dropped", server.URL)
+			continue
+		}
+
+		if parsed.Scheme != "" && !utils.Contains(result.Schemes, parsed.Scheme) {
+			result.Schemes = append(result.Schemes, parsed.Scheme)
+		}
+	}
+	result.Host, result.BasePath = host, basePath
+}
+
+// server URL with its {variables} replaced by their defaults
+func serverURL(server openapi.Server) string {
+	serverURL := server.URL
+	for _, name := range utils.SortedKeys(server.Variables) {
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", server.Variables[name].Default)
+	}
+	return serverURL
+}
+
+// converts the security schemes into securityDefinitions and returns the names
+// of the schemes that could not be converted
+func (c *swagger2Converter) convertSecuritySchemes(schemes map[string]*openapi.SecurityScheme) map[string]bool {
+	dropped := map[string]bool{}
+	for _, name := range utils.SortedKeys(schemes) {
+		scheme := schemes[name]
+		if scheme == nil {
+			continue
+		}
+		definition := &swagger2SecurityScheme{}
+
+		switch scheme.Type {
+		case "http":
+			switch strings.ToLower(scheme.Scheme) {
+			case "basic":
+				definition.Type = "basic"
+			case "bearer":
+				definition.Type, definition.Name, definition.In = "apiKey", "Authorization", "header"
+				c.warn("bearer scheme %s is described as an Authorization header API key", name)
+			default:
+				c.warn("http scheme %s (%s) is not supported and was dropped", name, scheme.Scheme)
+				dropped[name] = true
+				continue
+			}
+		case "apiKey":
+			if scheme.In == "cookie" {
+				c.warn("cookie API key %s is not supported and was dropped", name)
+				dropped[name] = true
+				continue
+			}
+			definition.Type, definition.Name, definition.In = "apiKey", scheme.Name, scheme.In
+		case "oauth2":
+			if !c.convertOAuthFlows(name, scheme.Flows, definition) {
+				dropped[name] = true
+				continue
+			}
+		default:
+			c.warn("%s scheme %s is not supported and was dropped", scheme.Type, name)
+			dropped[name] = true
+			continue
+		}
+
+		definition.Description = scheme.Description
+		c.securityDefinitions[name] = definition
+	}
+	return dropped
+}
+
+// Swagger 2.0 has a single flow per scheme, the first one of this list is kept
+func (c *swagger2Converter) convertOAuthFlows(name string, flows *openapi.OAuthFlows, definition *swagger2SecurityScheme) bool {
+	if flows == nil {
+		flows = &openapi.OAuthFlows{}
+	}
+	flowNames := []struct {
+		key, flow string
+		value     *openapi.OAuthFlow
+	}{
+		{"authorizationCode", "accessCode", flows.AuthorizationCode},
+		{"clientCredentials", "application", flows.ClientCredentials},
+		{"password", "password", flows.Password},
+		{"implicit", "implicit", flows.Implicit},
+	}
+
+	declared := 0
+	for _, names := range flowNames {
+		if names.value != nil {
+			declared++
+		}
+	}
+
+	for _, names := range flowNames {
+		flow := names.value
+		if flow == nil {
+			continue
+		}
+		if declared > 1 {
+			c.warn("oauth2 scheme %s has several flows, only %s is kept", name, names.key)
+		}
+
+		definition.Type = "oauth2"
+		definition.Flow = names.flow
+		definition.AuthorizationURL = flow.AuthorizationURL
+		definition.TokenURL = flow.TokenURL
+		// scopes are required by 2.0, even when there are none
+		definition.Scopes = flow.Scopes
+		if definition.Scopes == nil {
+			definition.Scopes = map[string]string{}
+		}
+		return true
+	}
+
+	c.warn("oauth2 scheme %s has no supported flow and was dropped", name)
+	return false
+}
+
+// removes requirements that use a dropped scheme
+func (c *swagger2Converter) convertSecurity(security []openapi.SecurityRequirement, dropped map[string]bool, owner string) []openapi.SecurityRequirement {
+	result := []openapi.SecurityRequirement{}
+	for _, requirement := range security {
+		usable := true
+		for name := range requirement {
+			if dropped[name] {
+				usable = false
+			}
+		}
+		if !usable {
+			c.warn("%s: a requirement using an unsupported scheme was dropped", owner)
+			continue
+		}
+		result = append(result, requirement)
+	}
+	return result
+}
+
+func (c *swagger2Converter) convertPathItem(path string, item *openapi.PathItem, dropped map[string]bool) *swagger2PathItem {
+	if item == nil {
+		return &swagger2PathItem{}
+	}
+
+	operation := func(method string, operation *openapi.Operation) *swagger2Operation {
+		return c.convertOperation(method+" "+path, operation, dropped)
+	}
+	return &swagger2PathItem{
+		Parameters: c.convertParameters(item.Parameters, path),
+		Get:        operation("GET", item.Get),
+		Put:        operation("PUT", item.Put),
+		Post:       operation("POST", item.Post),
+		Delete:     operation("DELETE", item.Delete),
+		Options:    operation("OPTIONS", item.Options),
+		Head:       operation("HEAD", item.Head),
+		Patch:      operation("PATCH", item.Patch),
+		Trace:      operation("TRACE", item.Trace),
+	}
+}
+
+func (c *swagger2Converter) convertOperation(owner string, operation *openapi.Operation, dropped map[string]bool) *swagger2Operation {
+	if operation == nil {
+		return nil
+	}
+
+	result := &swagger2Operation{
+		Tags:         operation.Tags,
+		Summary:      operation.Summary,
+		Description:  operation.Description,
+		ExternalDocs: operation.ExternalDocs,
+		OperationID:  operation.OperationID,
+		Parameters:   c.convertParameters(operation.Parameters, owner),
+		Responses:    map[string]*swagger2Response{},
+		Deprecated:   operation.Deprecated,
+	}
+
+	// the request body becomes the body parameter
+	if operation.RequestBody != nil {
+		result.Parameters = append(result.Parameters, c.convertRequestBody(owner, operation.RequestBody))
+	}
+
+	for code, response := range operation.Responses {
+		result.Responses[code] = c.convertResponse(owner+" response "+code, response)
+	}
+
+	if len(operation.Callbacks) > 0 {
+		c.warn("%s: callbacks are not supported and were dropped", owner)
+	}
+
+	// an empty list marks the operation as public, so it is only kept when the
+	// operation disabled security itself
+	if operation.Security != nil {
+		security := c.convertSecurity(*operation.Security, dropped, owner)
+		if len(security) > 0 || len(*operation.Security) == 0 {
+			result.Security = &security
+		} else {
+			c.warn("%s: no security requirement is supported, the global security applies instead", owner)
+		}
+	}
+	return result
+}
+
+func (c *swagger2Converter) convertParameters(params []*openapi.Parameter, owner string) []*swagger2Parameter {
+	result := []*swagger2Parameter{}
+	for _, param := range params {
+		if converted := c.convertParameter(param, owner); converted != nil {
+			result = append(result, converted)
+		}
+	}
+	return result
+}
+
+// flattens the parameter schema into the parameter, as Swagger 2.0 expects.
+// examples, style and explode have no 2.0 equivalent for non-body parameters
+func (c *swagger2Converter) convertParameter(param *openapi.Parameter, owner string) *swagger2Parameter {
+	if param == nil {
+		return nil
+	}
+	if param.Ref != "" {
+		return &swagger2Parameter{Ref: swagger2Ref(param.Ref)}
+	}
+
+	if param.In == "cookie" {
+		c.warn("%s: cookie parameter %s is not supported and was dropped", owner, param.Name)
+		return nil
+	}
+
+	return &swagger2Parameter{
+		Name:          param.Name,
+		In:            param.In,
+		Description:   param.Description,
+		Required:      param.Required,
+		swagger2Items: c.flattenSchema(owner+" parameter "+param.Name, param.Schema),
+	}
+}
+
+// the keywords of a primitive schema, for a parameter or header
+func (c *swagger2Converter) flattenSchema(owner string, schema *openapi.Schema) swagger2Items {
+	if schema == nil {
+		return swagger2Items{}
+	}
+	if schema.Ref != "" {
+		c.warn("%s: schema references are only allowed in bodies, described as a string", owner)
+		return swagger2Items{Type: "string"}
+	}
+
+	items := swagger2Items{
+		Format:  schema.Format,
+		Enum:    schema.Enum,
+		Default: schema.Default,
+	}
+	if len(schema.Type) > 0 {
+		items.Type = schema.Type[0]
+	}
+	if schema.Items != nil {
+		flattened := c.flattenSchema(owner, schema.Items)
+		items.Items = &flattened
+	}
+	return items
+}
+
+func (c *swagger2Converter) convertRequestBody(owner string, body *openapi.RequestBody) *swagger2Parameter {
+	if body.Ref != "" {
+		return &swagger2Parameter{Ref: swagger2Ref(body.Ref)}
+	}
+
+	param := &swagger2Parameter{
+		Name:        "body",
+		In:          "body",
+		Description: body.Description,
+		Required:    body.Required,
+	}
+
+	if media := c.jsonMedia(owner, body.Content); media != nil {
+		param.Schema = c.convertSchema(owner, media.Schema)
+		if media.Example != nil || len(media.Examples) > 0 {
+			c.warn("%s: request body examples are not supported and were dropped", owner)
+		}
+	}
+	return param
+}
+
+func (c *swagger2Converter) convertResponse(owner string, response *openapi.Response) *swagger2Response {
+	if response == nil {
+		return &swagger2Response{}
+	}
+	if response.Ref != "" {
+		return &swagger2Response{Ref: swagger2Ref(response.Ref)}
+	}
+
+	result := &swagger2Response{Description: response.Description}
+
+	if media := c.jsonMedia(owner, response.Content); media != nil {
+		result.Schema = c.convertSchema(owner, media.Schema)
+
+		example := media.Example
+		if example == nil && len(media.Examples) > 0 {
+			// only one example per media type, the first named one is kept
+			names := utils.SortedKeys(media.Examples)
+			if first := media.Examples[names[0]]; first != nil {
+				example = first.Value
+			}
+			if len(names) > 1 {
+				c.warn("%s: only example %s is kept", owner, names[0])
+			}
+		}
+		if example != nil {
+			result.Examples = map[string]interface{}{"application/json": example}
+		}
+	}
+
+	if len(response.Headers) > 0 {
+		result.Headers = map[string]*swagger2Header{}
+		for name, header := range response.Headers {
+			result.Headers[name] = c.convertHeader(owner+" header "+name, header)
+		}
+	}
+
+	if len(response.Links) > 0 {
+		c.warn("%s: links are not supported and were dropped", owner)
+	}
+	return result
+}
+
+func (c *swagger2Converter) convertHeader(owner string, header *openapi.Header) *swagger2Header {
+	// headers cannot be shared in 2.0, references are inlined
+	if header != nil && header.Ref != "" {
+		var shared *openapi.Header
+		if c.document.Components != nil {
+			shared = c.document.Components.Headers[strings.TrimPrefix(header.Ref, "#/components/headers/")]
+		}
+		header = shared
+	}
+	if header == nil {
+		header = &openapi.Header{}
+	}
+
+	result := &swagger2Header{Description: header.Description}
+	if header.Schema == nil {
+		result.Type = "string"
+		return result
+	}
+	result.swagger2Items = c.flattenSchema(owner, header.Schema)
+	return result
+}
+
+// the application/json media type, or the first one declared. nil without content
+func (c *swagger2Converter) jsonMedia(owner string, content map[string]*openapi.MediaType) *openapi.MediaType {
+	if len(content) == 0 {
+		return nil
+	}
+	if media, ok := content["application/json"]; ok {
+		return media
+	}
+	first := utils.SortedKeys(content)[0]
+	c.warn("%s: media type %s is described as application/json", owner, first)
+	return content[first]
+}
+
+// moves the reusable components to their 2.0 locations
+func (c *swagger2Converter) convertComponents(result *swagger2Document, components *openapi.Components) {
+	if len(components.Schemas) > 0 {
+		result.Definitions = map[string]*openapi.Schema{}
+		for name, schema := range components.Schemas {
+			result.Definitions[name] = c.convertSchema(name, schema)
+		}
+	}
+
+	parameters := map[string]*swagger2Parameter{}
+	for name, param := range components.Parameters {
+		if converted := c.convertParameter(param, "parameter "+name); converted != nil {
+			parameters[name] = converted
+		}
+	}
+	// shared request bodies become body parameters
+	for _, name := range utils.SortedKeys(components.RequestBodies) {
+		body := components.RequestBodies[name]
+		if body == nil {
+			continue
+		}
+		if _, exists := parameters[name]; exists {
+			c.warn("request body %s clashes with the parameter of the same name", name)
+		}
+		parameters[name] = c.convertRequestBody("request body "+name, body)
+	}
+	if len(parameters) > 0 {
+		result.Parameters = parameters
+	}
+
+	if len(components.Responses) > 0 {
+		result.Responses = map[string]*swagger2Response{}
+		for name, response := range components.Responses {
+			result.Responses[name] = c.convertResponse("response "+name, response)
+		}
+	}
+
+	if len(c.securityDefinitions) > 0 {
+		result.SecurityDefinitions = c.securityDefinitions
+	}
+}
+
+// copy of a schema with its references moved to their 2.0 locations and the
+// OpenAPI 3.0 only keywords replaced
+func (c *swagger2Converter) convertSchema(owner string, schema *openapi.Schema) *openapi.Schema {
+	if schema == nil {
+		return nil
+	}
+
+	result := *schema
+	result.Ref = swagger2Ref(schema.Ref)
+	if schema.Nullable {
+		result.Nullable, result.XNullable = false, true
+	}
+
+	if schema.Properties != nil {
+		result.Properties = openapi.NewProperties()
+		for _, name := range schema.Properties.Names() {
+			property, _ := schema.Properties.Get(name)
+			result.Properties.Set(name, c.convertSchema(owner+"."+name, property))
+		}
+	}
+	result.Items = c.convertSchema(owner, schema.Items)
+	result.AdditionalProperties = c.convertSchema(owner, schema.AdditionalProperties)
+
+	result.AllOf = nil
+	for _, item := range schema.AllOf {
+		result.AllOf = append(result.AllOf, c.convertSchema(owner, item))
+	}
+
+	result.AnyOf, result.OneOf = nil, nil
+	for _, alternatives := range []struct {
+		keyword string
+		schemas []*openapi.Schema
+	}{{"anyOf", schema.AnyOf}, {"oneOf", schema.OneOf}} {
+		if len(alternatives.schemas) > 0 {
+			c.warn("%s: %s is not supported, the first alternative is used", owner, alternatives.keyword)
+			result.AllOf = []*openapi.Schema{c.convertSchema(owner, alternatives.schemas[0])}
+		}
+	}
+	return &result
+}
+
+func swagger2Ref(ref string) string {
+	for _, mapping := range swagger2Refs {
+		if strings.HasPrefix(ref, mapping.from) {
+			return mapping.to + strings.TrimPrefix(ref, mapping.from)
+		}
+	}
+	return ref
+}
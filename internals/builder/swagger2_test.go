@@ -0,0 +1,58 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/fanchann/docunyan/internals/openapi"
+)
+
+func TestSwagger2KeepsDroppedSecurityProtected(t *testing.T) {
+	requirements := func(names ...string) *[]openapi.SecurityRequirement {
+		security := []openapi.SecurityRequirement{}
+		for _, name := range names {
+			security = append(security, openapi.SecurityRequirement{name: {}})
+		}
+		return &security
+	}
+
+	tests := []struct {
+		name     string
+		security *[]openapi.SecurityRequirement
+		want     *[]openapi.SecurityRequirement // nil when the key is left out
+	}{
+		{name: "inherited", security: nil, want: nil},
+		{name: "disabled", security: requirements(), want: requirements()},
+		{name: "supported", security: requirements("session", "token"), want: requirements("token")},
+		{name: "all dropped", security: requirements("session"), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := &openapi.Document{
+				Paths: map[string]*openapi.PathItem{
+					"/me": {Get: &openapi.Operation{
+						Responses: map[string]*openapi.Response{"200": {Description: "ok"}},
+						Security:  tt.security,
+					}},
+				},
+				Components: &openapi.Components{
+					SecuritySchemes: map[string]*openapi.SecurityScheme{
+						"session": {Type: "apiKey", In: "cookie", Name: "sid"},
+						"token":   {Type: "apiKey", In: "header", Name: "X-Token"},
+					},
+				},
+			}
+
+			result, _ := convertToSwagger2(document)
+			got := result.Paths["/me"].Get.Security
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("security = %v, want it left out", *got)
+			case tt.want != nil && got == nil:
+				t.Errorf("security left out, want %v", *tt.want)
+			case tt.want != nil && len(*got) != len(*tt.want):
+				t.Errorf("security = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// component sections whose entries become local references when bundling
+var componentSections = [][]string{
+	{"components", "schemas"},
+	{"components", "responses"},
+	{"components", "parameters"},
+	{"components", "examples"},
+	{"components", "requestBodies"},
+	{"components", "headers"},
+	{"components", "securitySchemes"},
+	{"components", "links"},
+	{"components", "callbacks"},
+	{"definitions"},
+	{"parameters"},
+	{"responses"},
+}
+
+type bundler struct {
+	root       string                 // absolute path of the root file
+	documents  map[string]interface{} // loaded files by absolute path
+	components map[string]string      // external target (file#pointer) -> local reference
+	inlining   []string               // targets being inlined, for cycle detection
+}
+
+// reads a spec and inlines every reference to another file. files referenced by a
+// component of the root file become local references to that component, so shared
+// schemas are not copied
+func Bundle(rootPath string) (*utils.OrderedMap, error) {
+	root, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", rootPath, err)
+	}
+
+	b := &bundler{root: root, documents: map[string]interface{}{}, components: map[string]string{}}
+	document, err := b.load(root)
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := document.(*utils.OrderedMap)
+	if !ok {
+		return nil, fmt.Errorf("%s: spec must be a mapping", rootPath)
+	}
+
+	for _, section := range componentSections {
+		entries := spec
+		for _, key := range section {
+			entries = objectAt(entries, key)
+		}
+		for _, name := range entries.Keys() {
+			entry, _ := entries.Get(name)
+			ref, ok := externalRef(entry)
+			if !ok {
+				continue
+			}
+			target, err := b.target(root, ref)
+			if err != nil {
+				return nil, err
+			}
+			b.components[target] = localRef(append(append([]string{}, section...), name))
+		}
+	}
+
+	bundled, err := b.resolve(spec, root, nil)
+	if err != nil {
+		return nil, err
+	}
+	// a root that is a reference itself may resolve to anything
+	result, ok := bundled.(*utils.OrderedMap)
+	if !ok {
+		return nil, fmt.Errorf("%s: spec must resolve to a mapping", rootPath)
+	}
+	return result, nil
+}
+
+// replaces the references below value, file is the file value was read from and
+// location its keys in the bundled document
+func (b *bundler) resolve(value interface{}, file string, location []string) (interface{}, error) {
+	switch v := value.(type) {
+	case *utils.OrderedMap:
+		if ref, ok := refOf(v); ok {
+			return b.resolveRef(v, ref, file, location)
+		}
+
+		result := utils.NewOrderedMap()
+		for _, key := range v.Keys() {
+			child, _ := v.Get(key)
+			if ref, ok := child.(string); ok && key == "operationRef" {
+				// link targets are references too, but are never inlined
+				local, err := b.localize(ref, file)
+				if err != nil {
+					return nil, err
+				}
+				result.Set(key, local)
+				continue
+			}
+
+			resolved, err := b.resolve(child, file, append(location, key))
+			if err != nil {
+				return nil, err
+			}
+			result.Set(key, resolved)
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for i, item := range v {
+			resolved, err := b.resolve(item, file, append(location, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, resolved)
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+func (b *bundler) resolveRef(object *utils.OrderedMap, ref, file string, location []string) (interface{}, error) {
+	target, err := b.target(file, ref)
+	if err != nil {
+		return nil, err
+	}
+	targetFile, pointer, _ := strings.Cut(target, "#")
+
+	// references into the root file stay local
+	if targetFile == b.root {
+		return withRef(object, "#"+pointer), nil
+	}
+
+	// shared components are referenced, except where the component itself is defined
+	if local, ok := b.components[target]; ok && local != localRef(location) {
+		return withRef(object, local), nil
+	}
+
+	for _, inlining := range b.inlining {
+		if inlining == target {
+			return nil, fmt.Errorf("%s: circular reference %s, declare it as a component to bundle it", utils.RelativePath(file), ref)
+		}
+	}
+	b.inlining = append(b.inlining, target)
+	defer func() { b.inlining = b.inlining[:len(b.inlining)-1] }()
+
+	document, err := b.load(targetFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", utils.RelativePath(file), err)
+	}
+	value, err := resolvePointer(document, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", utils.RelativePath(file), ref, err)
+	}
+	return b.resolve(value, targetFile, location)
+}
+
+// turns a reference of file into a local one when it points into the root file
+func (b *bundler) localize(ref, file string) (string, error) {
+	if strings.HasPrefix(ref, "#") && file == b.root {
+		return ref, nil
+	}
+	target, err := b.target(file, ref)
+	if err != nil {
+		return "", err
+	}
+	if targetFile, pointer, _ := strings.Cut(target, "#"); targetFile == b.root {
+		return "#" + pointer, nil
+	}
+	return ref, nil
+}
+
+// absolute form of a reference made from file, "file#pointer"
+func (b *bundler) target(file, ref string) (string, error) {
+	refFile, pointer, _ := strings.Cut(ref, "#")
+	if strings.Contains(refFile, "://") {
+		return "", fmt.Errorf("%s: remote reference %s is not supported", utils.RelativePath(file), ref)
+	}
+
+	targetFile := file
+	if refFile != "" {
+		targetFile = filepath.Clean(filepath.Join(filepath.Dir(file), filepath.FromSlash(refFile)))
+	}
+	return targetFile + "#" + pointer, nil
+}
+
+func (b *bundler) load(path string) (interface{}, error) {
+	if document, ok := b.documents[path]; ok {
+		return document, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", utils.RelativePath(path), err)
+	}
+	document, err := utils.DecodeOrderedYAML(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", utils.RelativePath(path), err)
+	}
+	b.documents[path] = document
+	return document, nil
+}
+
+// follows a JSON pointer such as /components/schemas/Order
+func resolvePointer(document interface{}, pointer string) (interface{}, error) {
+	current := document
+	if pointer == "" || pointer == "/" {
+		return current, nil
+	}
+
+	for _, segment := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		switch v := current.(type) {
+		case *utils.OrderedMap:
+			value, ok := v.Get(segment)
+			if !ok {
+				return nil, fmt.Errorf("%s not found", pointer)
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("%s not found", pointer)
+			}
+			current = v[index]
+		default:
+			return nil, fmt.Errorf("%s not found", pointer)
+		}
+	}
+	return current, nil
+}
+
+// $ref of an object
+func refOf(object *utils.OrderedMap) (string, bool) {
+	value, ok := object.Get("$ref")
+	if !ok {
+		return "", false
+	}
+	ref, ok := value.(string)
+	return ref, ok
+}
+
+// $ref of a value when it points at another file
+func externalRef(value interface{}) (string, bool) {
+	object, ok := value.(*utils.OrderedMap)
+	if !ok {
+		return "", false
+	}
+	ref, ok := refOf(object)
+	return ref, ok && !strings.HasPrefix(ref, "#")
+}
+
+// copy of a reference object pointing at ref, keeping sibling keys such as description
+func withRef(object *utils.OrderedMap, ref string) *utils.OrderedMap {
+	result := utils.NewOrderedMap()
+	for _, key := range object.Keys() {
+		value, _ := object.Get(key)
+		result.Set(key, value)
+	}
+	result.Set("$ref", ref)
+	return result
+}
+
+// local reference to a location of the bundled document, e.g. #/components/schemas/Order
+func localRef(location []string) string {
+	escaped := make([]string, 0, len(location))
+	for _, key := range location {
+		escaped = append(escaped, strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1"))
+	}
+	return "#/" + strings.Join(escaped, "/")
+}
@@ -0,0 +1,128 @@
+// Package bundle splits a spec into a directory of files joined by relative $refs
+// and bundles such a directory back into a single document.
+package bundle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+const (
+	schemasDir = "schemas"
+	pathsDir   = "paths"
+	rootName   = "openapi"
+)
+
+// a file of a split spec, Path is relative to the output directory
+type File struct {
+	Path    string
+	Content interface{}
+}
+
+// splits a spec into its root file, one file per schema and one file per path.
+// ext is the extension of every file, e.g. ".yaml". the spec is modified in place
+func Split(spec *utils.OrderedMap, ext string) []File {
+	schemaPrefix := "#/components/schemas/"
+	schemas := objectAt(objectAt(spec, "components"), "schemas")
+	if _, ok := spec.Get("swagger"); ok {
+		schemaPrefix = "#/definitions/"
+		schemas = objectAt(spec, "definitions")
+	}
+
+	rootFile := rootName + ext
+	files := []File{{Path: rootFile, Content: spec}}
+
+	// references inside a moved file are relative to its directory
+	rewrite := func(value interface{}, schemaDir string) {
+		rewriteRefs(value, func(ref string) string {
+			if name, ok := strings.CutPrefix(ref, schemaPrefix); ok && schemas.Len() > 0 {
+				if _, exists := schemas.Get(name); exists {
+					return schemaDir + name + ext
+				}
+			}
+			if strings.HasPrefix(ref, "#") {
+				return "../" + rootFile + ref
+			}
+			return ref
+		})
+	}
+
+	for _, name := range schemas.Keys() {
+		schema, _ := schemas.Get(name)
+		file := schemasDir + "/" + name + ext
+		rewrite(schema, "")
+		files = append(files, File{Path: file, Content: schema})
+		schemas.Set(name, reference(file))
+	}
+
+	paths := objectAt(spec, "paths")
+	used := map[string]bool{}
+	for _, path := range paths.Keys() {
+		item, _ := paths.Get(path)
+		file := pathsDir + "/" + uniqueName(pathFileName(path), used) + ext
+		rewrite(item, "../"+schemasDir+"/")
+		files = append(files, File{Path: file, Content: item})
+		paths.Set(path, reference(file))
+	}
+
+	return files
+}
+
+// file name of a path, e.g. /orders/{id} -> orders_{id}
+func pathFileName(path string) string {
+	name := strings.Trim(path, "/")
+	if name == "" {
+		return "root"
+	}
+	return strings.ReplaceAll(name, "/", "_")
+}
+
+// adds a numeric suffix to names that are already taken
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	// case-insensitive file systems would merge names that only differ in case
+	used[strings.ToLower(unique)] = true
+	return unique
+}
+
+func reference(ref string) *utils.OrderedMap {
+	refObj := utils.NewOrderedMap()
+	refObj.Set("$ref", ref)
+	return refObj
+}
+
+// replaces every $ref and link operationRef below value with the result of fn
+func rewriteRefs(value interface{}, fn func(string) string) {
+	switch v := value.(type) {
+	case *utils.OrderedMap:
+		for _, key := range v.Keys() {
+			child, _ := v.Get(key)
+			if ref, ok := child.(string); ok && (key == "$ref" || key == "operationRef") {
+				v.Set(key, fn(ref))
+				continue
+			}
+			rewriteRefs(child, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			rewriteRefs(item, fn)
+		}
+	}
+}
+
+// object at key, an empty object when it is missing
+func objectAt(object *utils.OrderedMap, key string) *utils.OrderedMap {
+	if object != nil {
+		if value, ok := object.Get(key); ok {
+			if child, ok := value.(*utils.OrderedMap); ok {
+				return child
+			}
+		}
+	}
+	return utils.NewOrderedMap()
+}
@@ -0,0 +1,246 @@
+package configschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+const schemaNameDef = "SchemaName"
+
+var (
+	pathItemType            = reflect.TypeOf(models.PathItem{})
+	requestBodyType         = reflect.TypeOf(models.RequestBody{})
+	exampleType             = reflect.TypeOf(models.Example{})
+	securityRequirementType = reflect.TypeOf(models.SecurityRequirement{})
+	schemeRequirementType   = reflect.TypeOf(models.SchemeRequirement{})
+	endpointDetailType      = reflect.TypeOf(models.EndpointDetail{})
+	responseType            = reflect.TypeOf(models.Response{})
+	queryParamsType         = reflect.TypeOf(models.QueryParams{})
+)
+
+// file path or list of file paths, globs allowed
+var includeSchema = map[string]interface{}{
+	"oneOf": []interface{}{
+		map[string]interface{}{"type": "string"},
+		map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+	},
+}
+
+// builds a JSON Schema (draft-07) describing docunyan.yml from the config models.
+// schemaNames, when given, become the allowed values of schema and requestBody
+func Generate(schemaNames []string) ([]byte, error) {
+	g := &generator{definitions: map[string]interface{}{}}
+
+	root := g.structSchema(reflect.TypeOf(models.DocunyanYAML{}))
+	properties := root["properties"].(map[string]interface{})
+	properties["include"] = includeSchema
+	properties["profiles"] = map[string]interface{}{
+		"type":                 "object",
+		"description":          "Overrides deep-merged over the config when selected with --profile",
+		"additionalProperties": map[string]interface{}{"type": "object"},
+	}
+
+	schemaName := map[string]interface{}{"type": "string"}
+	if len(schemaNames) > 0 {
+		names := append([]string{}, schemaNames...)
+		sort.Strings(names)
+		schemaName["enum"] = names
+	}
+	g.definitions[schemaNameDef] = schemaName
+
+	schema := map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "docunyan.yml",
+		"description": "Configuration file of docunyan",
+		"definitions": g.definitions,
+	}
+	for key, value := range root {
+		schema[key] = value
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+type generator struct {
+	definitions map[string]interface{}
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/definitions/" + name}
+}
+
+// schema for any Go type of the models
+func (g *generator) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case securityRequirementType:
+		g.define("SecurityRequirement", func() map[string]interface{} {
+			return map[string]interface{}{
+				"description": "Scheme name, list of schemes required together, or {scheme: [scopes]}",
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{"type": "array", "items": g.typeSchema(schemeRequirementType)},
+					map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					},
+				},
+			}
+		})
+		return ref("SecurityRequirement")
+	case schemeRequirementType:
+		g.define("SchemeRequirement", func() map[string]interface{} {
+			return map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{
+						"type":                 "object",
+						"minProperties":        1,
+						"maxProperties":        1,
+						"additionalProperties": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					},
+				},
+			}
+		})
+		return ref("SchemeRequirement")
+	case pathItemType:
+		g.define("PathItem", func() map[string]interface{} {
+			properties := map[string]interface{}{
+				"parameters": g.typeSchema(reflect.TypeOf([]models.Parameter{})),
+				"$include":   includeSchema,
+			}
+			for _, method := range utils.HTTPMethods {
+				properties[method] = g.typeSchema(endpointDetailType)
+			}
+			return map[string]interface{}{
+				"type":                 "object",
+				"properties":           properties,
+				"additionalProperties": false,
+			}
+		})
+		return ref("PathItem")
+	case queryParamsType:
+		return map[string]interface{}{
+			"type":                 "object",
+			"description":          "Query parameters as name: type, in declaration order",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		}
+	case requestBodyType:
+		g.define("RequestBody", func() map[string]interface{} {
+			return map[string]interface{}{
+				"oneOf": []interface{}{
+					ref(schemaNameDef),
+					g.structSchema(t),
+				},
+			}
+		})
+		return ref("RequestBody")
+	case exampleType:
+		g.define("Example", func() map[string]interface{} {
+			return map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string", "description": "Path to a JSON file"},
+					g.structSchema(t),
+				},
+			}
+		})
+		return ref("Example")
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		g.define(t.Name(), func() map[string]interface{} {
+			return g.structSchema(t)
+		})
+		return ref(t.Name())
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           map[string]interface{}{"$include": includeSchema},
+			"additionalProperties": g.typeSchema(t.Elem()),
+		}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.typeSchema(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// interface{} values such as inline examples accept anything
+		return map[string]interface{}{}
+	}
+}
+
+// object schema built from the yaml tags of a struct
+func (g *generator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{
+		"$include": includeSchema,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		properties[name] = g.fieldSchema(t, field)
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// field level overrides for values that are not described by their Go type alone
+func (g *generator) fieldSchema(owner reflect.Type, field reflect.StructField) map[string]interface{} {
+	switch {
+	case field.Name == "Schema" && (owner == responseType || owner == requestBodyType):
+		return ref(schemaNameDef)
+	case owner == endpointDetailType && field.Name == "Parameter":
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "object"},
+			},
+		}
+	case (owner == responseType || owner == requestBodyType) && field.Name == "Example":
+		return map[string]interface{}{
+			"description": "Inline value or path to a JSON file",
+		}
+	}
+	return g.typeSchema(field.Type)
+}
+
+// registers a named definition once, reserving the name first so recursive types terminate
+func (g *generator) define(name string, build func() map[string]interface{}) {
+	if _, exists := g.definitions[name]; exists {
+		return
+	}
+	g.definitions[name] = map[string]interface{}{}
+	g.definitions[name] = build()
+}
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/bundle"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// writes the spec as a directory: openapi.<ext> plus schemas/ and paths/ with one
+// file each, joined by relative $refs. YAML is the default format
+func writeSplit(output []byte, outputDir, format, defaultName string) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "", "yml":
+		format = "yaml"
+	case "json", "yaml":
+	default:
+		return fmt.Errorf("split output needs a single format, json or yaml, got %q", format)
+	}
+
+	if outputDir == "" {
+		outputDir = defaultName
+	}
+	if info, err := os.Stat(outputDir); err == nil && !info.IsDir() {
+		return fmt.Errorf("split output %s is a file, expected a directory", outputDir)
+	}
+
+	decoded, err := utils.DecodeOrderedJSON(output)
+	if err != nil {
+		return fmt.Errorf("failed to read generated spec: %w", err)
+	}
+	spec, ok := decoded.(*utils.OrderedMap)
+	if !ok {
+		return fmt.Errorf("generated spec is not an object")
+	}
+
+	ext := "." + format
+	files := bundle.Split(spec, ext)
+
+	// only files listed by the previous run are replaced or removed, anything else in
+	// the directory belongs to the user
+	previous, err := readSplitManifest(outputDir)
+	if err != nil {
+		return err
+	}
+	written := map[string]bool{}
+	for _, file := range files {
+		written[file.Path] = true
+		path := filepath.Join(outputDir, filepath.FromSlash(file.Path))
+		if _, err := os.Stat(path); err == nil && !previous[file.Path] {
+			return fmt.Errorf("split output would overwrite %s, which was not written by docunyan; remove it or choose another output directory", path)
+		}
+	}
+
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.Content, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", file.Path, err)
+		}
+		content, err = encodeOutput(content, format)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(outputDir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+	}
+
+	// files of schemas and paths that no longer exist would linger otherwise
+	for _, stale := range utils.SortedKeys(previous) {
+		if written[stale] {
+			continue
+		}
+		err := os.Remove(filepath.Join(outputDir, filepath.FromSlash(stale)))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove stale file: %w", err)
+		}
+	}
+
+	if err := writeSplitManifest(outputDir, files); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Swagger generated successfully at %s (%d files)\n", filepath.Join(outputDir, files[0].Path), len(files))
+	return nil
+}
+
+// lists the files written by the last split run, relative to the output directory
+const splitManifest = ".docunyan-split"
+
+// files listed in the manifest of a previous run, empty when there is none.
+// entries outside the directory are ignored
+func readSplitManifest(outputDir string) (map[string]bool, error) {
+	files := map[string]bool{}
+	content, err := os.ReadFile(filepath.Join(outputDir, splitManifest))
+	if os.IsNotExist(err) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read split manifest: %w", err)
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || !filepath.IsLocal(filepath.FromSlash(line)) {
+			continue
+		}
+		files[line] = true
+	}
+	return files, nil
+}
+
+func writeSplitManifest(outputDir string, files []bundle.File) error {
+	var content strings.Builder
+	content.WriteString("# files written by docunyan --split, removed again when they are no longer generated\n")
+	for _, file := range files {
+		content.WriteString(file.Path + "\n")
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, splitManifest), []byte(content.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write split manifest: %w", err)
+	}
+	return nil
+}
+
+// inlines the external $refs of a split spec into a single file
+func GenerateBundle(inputPath, outputPath, format string) error {
+	spec, err := bundle.Bundle(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to bundle %s: %w", inputPath, err)
+	}
+
+	output, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundled spec: %w", err)
+	}
+
+	defaultName := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath)) + "_bundled"
+	files, err := resolveOutputFiles(outputPath, format, defaultName)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		content, err := encodeOutput(output, file.format)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(file.path, content, 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		fmt.Printf("✅ Bundled spec written to %s\n", file.path)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// spec with one schema and one path per name
+func splitSpec(schemas, paths []string) []byte {
+	var spec strings.Builder
+	spec.WriteString(`{"openapi": "3.0.3", "info": {"title": "Shop API", "version": "1.0.0"}, "paths": {`)
+	for i, path := range paths {
+		if i > 0 {
+			spec.WriteString(",")
+		}
+		spec.WriteString(`"/` + path + `": {"get": {"responses": {"200": {"description": "ok"}}}}`)
+	}
+	spec.WriteString(`}, "components": {"schemas": {`)
+	for i, name := range schemas {
+		if i > 0 {
+			spec.WriteString(",")
+		}
+		spec.WriteString(`"` + name + `": {"type": "object"}`)
+	}
+	spec.WriteString(`}}}`)
+	return []byte(spec.String())
+}
+
+func TestWriteSplitKeepsFilesItDidNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "paths", "custom.yaml")
+	if err := os.MkdirAll(filepath.Dir(userFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userFile, []byte("user: file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeSplit(splitSpec([]string{"Order", "Product"}, []string{"orders", "products"}), dir, "yaml", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the second run no longer generates Product and /products
+	if err := writeSplit(splitSpec([]string{"Order"}, []string{"orders"}), dir, "yaml", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		file   string
+		exists bool
+	}{
+		{"openapi.yaml", true},
+		{"schemas/Order.yaml", true},
+		{"paths/orders.yaml", true},
+		{"schemas/Product.yaml", false},
+		{"paths/products.yaml", false},
+		{"paths/custom.yaml", true},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(tt.file)))
+		if exists := err == nil; exists != tt.exists {
+			t.Errorf("%s exists = %v, want %v", tt.file, exists, tt.exists)
+		}
+	}
+}
+
+func TestWriteSplitRefusesToOverwriteUserFiles(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "schemas", "Order.yaml")
+	if err := os.MkdirAll(filepath.Dir(userFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userFile, []byte("user: file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeSplit(splitSpec([]string{"Order"}, []string{"orders"}), dir, "yaml", "")
+	if err == nil || !strings.Contains(err.Error(), "was not written by docunyan") {
+		t.Fatalf("expected an overwrite error, got %v", err)
+	}
+
+	content, err := os.ReadFile(userFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "user: file\n" {
+		t.Errorf("user file was changed to %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "openapi.yaml")); err == nil {
+		t.Errorf("openapi.yaml was written despite the error")
+	}
+}
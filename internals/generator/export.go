@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/bundle"
+	"github.com/fanchann/docunyan/internals/htmldoc"
+	"github.com/fanchann/docunyan/internals/parser"
+	"github.com/fanchann/docunyan/internals/postman"
+)
+
+// writes a Postman Collection v2.1 built from the same inputs as the spec
+func ExportPostman(configPath, goFilePath, outputPath string, opts Options) error {
+	document, err := parser.ParseDocument(configPath, goFilePath, parser.Options{
+		Profile: opts.Profile,
+		Lenient: opts.Lenient,
+		OpenAPI: opts.OpenAPI,
+	})
+	if err != nil {
+		return fmt.Errorf("failed while parsing configuration: %w", err)
+	}
+
+	collection, warnings := postman.Build(document)
+	for _, warning := range warnings {
+		log.Printf("warning: %s", warning)
+	}
+
+	output, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode collection: %w", err)
+	}
+
+	if outputPath == "" {
+		outputPath = strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath)) + ".postman_collection.json"
+	}
+	if err := os.WriteFile(outputPath, output, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	fmt.Printf("✅ Postman collection exported at %s\n", outputPath)
+	return nil
+}
+
+// writes the spec built from the config as a single HTML page that needs no server
+// or network, e.g. to attach to a release
+func ExportHTML(configPath, goFilePath, outputPath string, opts Options) error {
+	spec, err := parser.DocunyanConfigParser(configPath, goFilePath, parser.Options{
+		Profile: opts.Profile,
+		Lenient: opts.Lenient,
+		OpenAPI: opts.OpenAPI,
+	})
+	if err != nil {
+		return fmt.Errorf("failed while parsing configuration: %w", err)
+	}
+
+	if outputPath == "" {
+		outputPath = strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath)) + ".html"
+	}
+	return writeHTML(spec, outputPath)
+}
+
+// writes an existing spec as a single HTML page, split specs are bundled first
+func ExportSpecHTML(specPath, outputPath string) error {
+	spec, err := bundle.Bundle(specPath)
+	if err != nil {
+		return fmt.Errorf("failed to load spec: %w", err)
+	}
+	output, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode spec: %w", err)
+	}
+
+	if outputPath == "" {
+		outputPath = strings.TrimSuffix(filepath.Base(specPath), filepath.Ext(specPath)) + ".html"
+	}
+	return writeHTML(output, outputPath)
+}
+
+func writeHTML(spec []byte, outputPath string) error {
+	page, err := htmldoc.Render(spec)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, page, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	fmt.Printf("✅ HTML documentation exported at %s\n", outputPath)
+	return nil
+}
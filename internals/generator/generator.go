@@ -3,25 +3,177 @@ package generator
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/fanchann/docunyan/internals/configschema"
 	"github.com/fanchann/docunyan/internals/parser"
+	"github.com/fanchann/docunyan/internals/utils"
 )
 
-func GenerateSwagger(configPath, goFilePath, outputPath string) error {
-	outputTempl, err := parser.DocunyanConfigParser(configPath, goFilePath)
+// optional settings of a generation run
+type Options struct {
+	Profile string // profile from the config's profiles section
+	Lenient bool   // warn about unknown config keys instead of failing
+	Format  string // json, yaml or both comma separated, e.g. "json,yaml" (default from the output extension)
+	OpenAPI string // OpenAPI version of the document, 3.0 (default), 3.1 or 2.0 (Swagger)
+	Split   bool   // write a directory with one file per schema and path instead of a single file
+}
+
+// a file written by a generation run
+type outputFile struct {
+	path   string
+	format string
+}
+
+func GenerateSwagger(configPath, goFilePath, outputPath string, opts Options) error {
+	outputTempl, err := parser.DocunyanConfigParser(configPath, goFilePath, parser.Options{
+		Profile: opts.Profile,
+		Lenient: opts.Lenient,
+		OpenAPI: opts.OpenAPI,
+	})
 	if err != nil {
 		return fmt.Errorf("failed while parsing configuration: %w", err)
 	}
 
+	defaultName := fmt.Sprintf("docunyan_gen_%s", time.Now().Format("20060102_150405"))
+	if opts.Profile != "" {
+		defaultName = fmt.Sprintf("docunyan_gen_%s_%s", opts.Profile, time.Now().Format("20060102_150405"))
+	}
+
+	if opts.Split {
+		return writeSplit(outputTempl, outputPath, opts.Format, defaultName)
+	}
+
+	files, err := resolveOutputFiles(outputPath, opts.Format, defaultName)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		output, err := encodeOutput(outputTempl, file.format)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(file.path, output, 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+
+		fmt.Printf("✅ Swagger generated successfully at %s\n", file.path)
+	}
+	return nil
+}
+
+// decides which files to write from --format and the extension of --output.
+// several formats share the output path without its extension
+func resolveOutputFiles(outputPath, format, defaultName string) ([]outputFile, error) {
+	formats := []string{}
+	for _, f := range strings.Split(format, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		switch f {
+		case "":
+			continue
+		case "yml":
+			f = "yaml"
+		case "json", "yaml":
+		default:
+			return nil, fmt.Errorf("unknown output format %q, expected json or yaml", f)
+		}
+		if !utils.Contains(formats, f) {
+			formats = append(formats, f)
+		}
+	}
+
+	ext := filepath.Ext(outputPath)
+	extFormat := formatOfExtension(ext)
+
+	if outputPath == "" {
+		if len(formats) == 0 {
+			formats = []string{"json"}
+		}
+		files := []outputFile{}
+		for _, f := range formats {
+			files = append(files, outputFile{path: defaultName + "." + f, format: f})
+		}
+		return files, nil
+	}
+
+	switch len(formats) {
+	case 0:
+		if extFormat == "" {
+			extFormat = "json"
+		}
+		return []outputFile{{path: outputPath, format: extFormat}}, nil
+	case 1:
+		if extFormat != "" && extFormat != formats[0] {
+			return nil, fmt.Errorf("output %s does not match format %s", outputPath, formats[0])
+		}
+		return []outputFile{{path: outputPath, format: formats[0]}}, nil
+	}
+
+	base := outputPath
+	if extFormat != "" {
+		base = strings.TrimSuffix(outputPath, ext)
+	}
+	files := []outputFile{}
+	for _, f := range formats {
+		files = append(files, outputFile{path: base + "." + f, format: f})
+	}
+	return files, nil
+}
+
+// converts the JSON output to the requested format
+func encodeOutput(output []byte, format string) ([]byte, error) {
+	if format != "yaml" {
+		return output, nil
+	}
+	encoded, err := utils.JSONToYAML(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	return encoded, nil
+}
+
+func formatOfExtension(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return ""
+}
+
+// writes the JSON Schema of docunyan.yml, using the structs of goFilePath (optional)
+// as the allowed schema names
+func GenerateConfigSchema(goFilePath, outputPath string) error {
+	schemaNames := []string{}
+	if goFilePath != "" {
+		schemaBuilder := parser.NewSchemaBuilder()
+		if err := schemaBuilder.ParseGoStructs(goFilePath); err != nil {
+			return fmt.Errorf("failed to parse Go structs: %w", err)
+		}
+		for name := range schemaBuilder.Structs {
+			schemaNames = append(schemaNames, name)
+		}
+	}
+
+	output, err := configschema.Generate(schemaNames)
+	if err != nil {
+		return fmt.Errorf("failed to build config schema: %w", err)
+	}
+
 	if outputPath == "" {
-		outputPath = fmt.Sprintf("docunyan_gen_%s.json", time.Now().Format("20060102_150405"))
+		fmt.Println(string(output))
+		return nil
 	}
 
-	if err := os.WriteFile(outputPath, outputTempl, 0644); err != nil {
+	if err := os.WriteFile(outputPath, output, 0644); err != nil {
 		return fmt.Errorf("failed to write output file: %w", err)
 	}
 
-	fmt.Printf("✅ Swagger generated successfully at %s\n", outputPath)
+	fmt.Printf("✅ Config schema generated successfully at %s\n", outputPath)
 	return nil
 }
@@ -0,0 +1,55 @@
+// Package htmldoc renders a spec into a single HTML page that works offline: the
+// renderer script and stylesheet are embedded and the spec is inlined.
+package htmldoc
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+//go:embed page.html
+var page string
+
+//go:embed style.css
+var style string
+
+//go:embed render.js
+var script string
+
+// renders a JSON spec (OpenAPI 3.x or Swagger 2.0) into a standalone HTML page
+func Render(spec []byte) ([]byte, error) {
+	var info struct {
+		Info struct {
+			Title string `json:"title"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(spec, &info); err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+	title := info.Info.Title
+	if title == "" {
+		title = "API documentation"
+	}
+
+	// the spec sits in a script element, so "</script>" in a description must not
+	// end it early. HTMLEscape turns < > & into \u003c and friends, which JSON.parse
+	// reads back unchanged
+	var inlined bytes.Buffer
+	if err := json.Compact(&inlined, spec); err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+	var escaped bytes.Buffer
+	json.HTMLEscape(&escaped, inlined.Bytes())
+
+	rendered := strings.NewReplacer(
+		"{{title}}", html.EscapeString(title),
+		"{{style}}", style,
+		"{{script}}", script,
+		"{{spec}}", escaped.String(),
+	).Replace(page)
+	return []byte(rendered), nil
+}
@@ -0,0 +1,577 @@
+(function () {
+  "use strict";
+
+  var spec = JSON.parse(document.getElementById("spec").textContent);
+  var swagger2 = spec.swagger === "2.0";
+  var methods = ["get", "put", "post", "delete", "options", "head", "patch", "trace"];
+  var maxDepth = 6;
+
+  // dom helpers, text is always set through textContent so the spec cannot inject markup
+  function el(tag, attrs) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (key) {
+      if (attrs[key] !== undefined && attrs[key] !== null) node.setAttribute(key, attrs[key]);
+    });
+    append.apply(null, [node].concat(Array.prototype.slice.call(arguments, 2)));
+    return node;
+  }
+
+  function append(node) {
+    for (var i = 1; i < arguments.length; i++) {
+      var child = arguments[i];
+      if (child === undefined || child === null || child === false) continue;
+      if (Array.isArray(child)) {
+        child.forEach(function (c) { append(node, c); });
+        continue;
+      }
+      node.appendChild(typeof child === "object" ? child : document.createTextNode(String(child)));
+    }
+  }
+
+  // paragraphs split on blank lines, `code` spans kept
+  function description(value) {
+    if (!value) return null;
+    var block = el("div", { class: "description" });
+    String(value).split(/\n\s*\n/).forEach(function (paragraph) {
+      var p = el("p");
+      paragraph.split(/(`[^`]+`)/).forEach(function (part) {
+        if (/^`[^`]+`$/.test(part)) append(p, el("code", null, part.slice(1, -1)));
+        else append(p, part);
+      });
+      append(block, p);
+    });
+    return block;
+  }
+
+  function anchor(prefix, value) {
+    return prefix + "-" + String(value).replace(/[^A-Za-z0-9_-]+/g, "-").replace(/^-+|-+$/g, "");
+  }
+
+  // references
+
+  function pointer(ref) {
+    if (typeof ref !== "string" || ref.charAt(0) !== "#") return undefined;
+    var current = spec;
+    var segments = ref.slice(1).split("/").slice(1);
+    for (var i = 0; i < segments.length; i++) {
+      var key = segments[i].replace(/~1/g, "/").replace(/~0/g, "~");
+      if (current === null || typeof current !== "object" || !(key in current)) return undefined;
+      current = current[key];
+    }
+    return current;
+  }
+
+  function refName(ref) {
+    var segments = String(ref).split("/");
+    return segments[segments.length - 1].replace(/~1/g, "/").replace(/~0/g, "~");
+  }
+
+  function isSchemaRef(ref) {
+    return /^#\/(components\/schemas|definitions)\//.test(ref);
+  }
+
+  // follows references of parameters, responses and other components
+  function deref(value) {
+    for (var i = 0; value && value.$ref && i < 10; i++) {
+      var target = pointer(value.$ref);
+      if (target === undefined) return value;
+      value = target;
+    }
+    return value;
+  }
+
+  // schemas
+
+  function typeLabel(schema) {
+    if (!schema) return "any";
+    if (schema.$ref) return refName(schema.$ref);
+    var type = schema.type;
+    if (Array.isArray(type)) type = type.filter(function (t) { return t !== "null"; }).join(" | ") + (type.indexOf("null") >= 0 ? " | null" : "");
+    if (!type) {
+      if (schema.properties) type = "object";
+      else if (schema.items) type = "array";
+      else if (schema.allOf) type = "allOf";
+      else if (schema.oneOf) type = "oneOf";
+      else if (schema.anyOf) type = "anyOf";
+      else type = "any";
+    }
+    if (type === "array") type = "array of " + typeLabel(schema.items);
+    if (schema.format) type += " (" + schema.format + ")";
+    if (schema.nullable || schema["x-nullable"]) type += " | null";
+    return type;
+  }
+
+  function constraints(schema) {
+    var parts = [];
+    if (schema.enum) parts.push("one of " + schema.enum.map(function (v) { return JSON.stringify(v); }).join(", "));
+    if (schema.const !== undefined) parts.push("always " + JSON.stringify(schema.const));
+    if (schema.default !== undefined) parts.push("default " + JSON.stringify(schema.default));
+    [["minimum", "≥ "], ["maximum", "≤ "], ["exclusiveMinimum", "> "], ["exclusiveMaximum", "< "],
+      ["minLength", "min length "], ["maxLength", "max length "], ["minItems", "min items "],
+      ["maxItems", "max items "], ["pattern", "pattern "]].forEach(function (c) {
+      if (typeof schema[c[0]] === "number" || typeof schema[c[0]] === "string") parts.push(c[1] + schema[c[0]]);
+    });
+    if (schema.uniqueItems) parts.push("unique items");
+    if (schema.readOnly) parts.push("read only");
+    if (schema.writeOnly) parts.push("write only");
+    return parts.length ? el("div", { class: "constraints" }, parts.join(" · ")) : null;
+  }
+
+  function typeNode(schema) {
+    if (schema && schema.$ref && isSchemaRef(schema.$ref)) {
+      return el("a", { class: "type", href: "#" + anchor("schema", refName(schema.$ref)) }, refName(schema.$ref));
+    }
+    if (schema && schema.items && schema.items.$ref && isSchemaRef(schema.items.$ref)) {
+      return el("span", { class: "type" }, "array of ",
+        el("a", { href: "#" + anchor("schema", refName(schema.items.$ref)) }, refName(schema.items.$ref)));
+    }
+    return el("span", { class: "type" }, typeLabel(schema));
+  }
+
+  // a schema with its properties, referenced schemas are expanded on demand
+  function renderSchema(schema, depth, seen) {
+    depth = depth || 0;
+    seen = seen || [];
+    var node = el("div", { class: "schema" });
+    if (!schema) return node;
+
+    if (schema.$ref) {
+      var target = pointer(schema.$ref);
+      append(node, typeNode(schema));
+      if (target && depth < maxDepth && seen.indexOf(schema.$ref) < 0) {
+        var details = el("details", null, el("summary", { class: "muted" }, "expand"));
+        var expanded = false;
+        details.addEventListener("toggle", function () {
+          if (expanded) return;
+          expanded = true;
+          append(details, renderSchema(target, depth + 1, seen.concat([schema.$ref])));
+        });
+        append(node, details);
+      } else if (seen.indexOf(schema.$ref) >= 0) {
+        append(node, el("span", { class: "muted" }, " (recursive)"));
+      }
+      return node;
+    }
+
+    append(node, el("div", null, typeNode(schema), schema.deprecated ? [" ", el("span", { class: "badge warn" }, "deprecated")] : null));
+    append(node, description(schema.description), constraints(schema));
+    if (depth >= maxDepth) return node;
+
+    ["allOf", "oneOf", "anyOf"].forEach(function (keyword) {
+      if (!Array.isArray(schema[keyword])) return;
+      append(node, el("h5", null, keyword));
+      schema[keyword].forEach(function (part) { append(node, renderSchema(part, depth + 1, seen)); });
+    });
+
+    var properties = schema.properties || {};
+    var required = schema.required || [];
+    var names = Object.keys(properties);
+    if (names.length) {
+      var rows = names.map(function (name) {
+        return el("tr", null,
+          el("td", { class: "name" }, name, required.indexOf(name) >= 0 ? el("span", { class: "required", title: "required" }, "*") : null),
+          el("td", null, nested(properties[name]) ? renderSchema(properties[name], depth + 1, seen) : [
+            typeNode(properties[name]),
+            description(properties[name].description),
+            constraints(properties[name])
+          ]));
+      });
+      append(node, el("table", null, el("tbody", null, rows)));
+    }
+
+    if (schema.items && nested(schema.items)) {
+      append(node, el("h5", null, "items"), renderSchema(schema.items, depth + 1, seen));
+    }
+    if (schema.additionalProperties && typeof schema.additionalProperties === "object") {
+      append(node, el("h5", null, "additional properties"), renderSchema(schema.additionalProperties, depth + 1, seen));
+    }
+    return node;
+  }
+
+  // whether a schema needs more than a single row to show
+  function nested(schema) {
+    if (!schema) return false;
+    if (schema.$ref) return !!pointer(schema.$ref);
+    return !!(schema.properties || schema.allOf || schema.oneOf || schema.anyOf ||
+      (schema.items && nested(schema.items)) || typeof schema.additionalProperties === "object");
+  }
+
+  // examples, the same rules as docunyan's own sample values
+
+  function sample(schema, visiting) {
+    visiting = visiting || [];
+    if (!schema) return null;
+    if (schema.$ref) {
+      var target = pointer(schema.$ref);
+      if (!target || visiting.indexOf(schema.$ref) >= 0) return null;
+      return sample(target, visiting.concat([schema.$ref]));
+    }
+    if (schema.example !== undefined) return schema.example;
+    if (Array.isArray(schema.examples) && schema.examples.length) return schema.examples[0];
+    if (schema.const !== undefined) return schema.const;
+    if (schema.default !== undefined) return schema.default;
+    if (Array.isArray(schema.enum) && schema.enum.length) return schema.enum[0];
+    if (Array.isArray(schema.allOf) && schema.allOf.length) {
+      var merged = {};
+      for (var i = 0; i < schema.allOf.length; i++) {
+        var part = sample(schema.allOf[i], visiting);
+        if (part === null || typeof part !== "object" || Array.isArray(part)) return part;
+        Object.keys(part).forEach(function (key) { merged[key] = part[key]; });
+      }
+      return merged;
+    }
+    if (Array.isArray(schema.oneOf) && schema.oneOf.length) return sample(schema.oneOf[0], visiting);
+    if (Array.isArray(schema.anyOf) && schema.anyOf.length) return sample(schema.anyOf[0], visiting);
+
+    var type = Array.isArray(schema.type) ? schema.type.filter(function (t) { return t !== "null"; })[0] : schema.type;
+    if (!type && schema.properties) type = "object";
+    switch (type) {
+      case "object":
+        var object = {};
+        Object.keys(schema.properties || {}).forEach(function (name) {
+          object[name] = sample(schema.properties[name], visiting);
+        });
+        return object;
+      case "array":
+        return schema.items ? [sample(schema.items, visiting)] : [];
+      case "integer":
+      case "number":
+        return 0;
+      case "boolean":
+        return true;
+      case "string":
+        switch (schema.format) {
+          case "date-time": return "2024-01-01T00:00:00Z";
+          case "date": return "2024-01-01";
+          case "email": return "user@example.com";
+          case "uuid": return "3fa85f64-5717-4562-b3fc-2c963f66afa6";
+          case "uri":
+          case "url": return "https://example.com";
+        }
+        return "string";
+    }
+    return null;
+  }
+
+  function exampleOf(media, schema) {
+    if (media && media.example !== undefined) return media.example;
+    if (media && media.examples) {
+      var names = Object.keys(media.examples);
+      if (names.length) {
+        var example = deref(media.examples[names[0]]);
+        if (example && example.value !== undefined) return example.value;
+      }
+    }
+    return sample(schema);
+  }
+
+  function exampleBlock(value) {
+    if (value === null || value === undefined) return null;
+    return el("pre", null, el("code", null, typeof value === "string" ? value : JSON.stringify(value, null, 2)));
+  }
+
+  // content of request bodies and responses, one block per media type
+  function renderContent(content) {
+    return Object.keys(content || {}).map(function (mediaType) {
+      var media = content[mediaType] || {};
+      return el("div", null,
+        el("div", { class: "muted" }, el("code", null, mediaType)),
+        media.schema ? renderSchema(media.schema) : null,
+        exampleBlock(exampleOf(media, media.schema)));
+    });
+  }
+
+  // operations
+
+  function collectOperations(paths, webhook) {
+    var operations = [];
+    Object.keys(paths || {}).forEach(function (path) {
+      var item = deref(paths[path]) || {};
+      methods.forEach(function (method) {
+        if (!item[method]) return;
+        operations.push({
+          path: path,
+          method: method,
+          operation: item[method],
+          parameters: mergeParameters(item.parameters, item[method].parameters),
+          webhook: webhook
+        });
+      });
+    });
+    return operations;
+  }
+
+  // operation parameters override path parameters with the same name and location
+  function mergeParameters(pathParameters, operationParameters) {
+    var merged = [];
+    (pathParameters || []).concat(operationParameters || []).forEach(function (parameter) {
+      parameter = deref(parameter);
+      if (!parameter) return;
+      merged = merged.filter(function (p) { return !(p.name === parameter.name && p.in === parameter.in); });
+      merged.push(parameter);
+    });
+    return merged;
+  }
+
+  function operationId(entry) {
+    return anchor(entry.webhook ? "webhook" : "op", entry.operation.operationId || entry.method + "-" + entry.path);
+  }
+
+  function renderParameters(parameters) {
+    var rows = parameters.filter(function (p) { return p.in !== "body"; }).map(function (p) {
+      var schema = p.schema || p;
+      return el("tr", null,
+        el("td", { class: "name" }, p.name, p.required ? el("span", { class: "required", title: "required" }, "*") : null),
+        el("td", null, p.in),
+        el("td", null, typeNode(schema), p.deprecated ? [" ", el("span", { class: "badge warn" }, "deprecated")] : null),
+        el("td", null, description(p.description), constraints(schema),
+          p.example !== undefined ? el("div", { class: "muted" }, "example: ", el("code", null, JSON.stringify(p.example))) : null));
+    });
+    if (!rows.length) return null;
+    return [el("h5", null, "Parameters"), el("table", null,
+      el("thead", null, el("tr", null, el("th", null, "Name"), el("th", null, "In"), el("th", null, "Type"), el("th", null, "Description"))),
+      el("tbody", null, rows))];
+  }
+
+  function renderRequestBody(entry) {
+    var operation = entry.operation;
+    if (swagger2) {
+      var body = entry.parameters.filter(function (p) { return p.in === "body"; })[0];
+      if (!body) return null;
+      var consumes = operation.consumes || spec.consumes || ["application/json"];
+      return [el("h5", null, "Request body", body.required ? el("span", { class: "required", title: "required" }, "*") : null),
+        description(body.description),
+        el("div", { class: "muted" }, el("code", null, consumes.join(", "))),
+        renderSchema(body.schema),
+        exampleBlock(sample(body.schema))];
+    }
+    var requestBody = deref(operation.requestBody);
+    if (!requestBody) return null;
+    return [el("h5", null, "Request body", requestBody.required ? el("span", { class: "required", title: "required" }, "*") : null),
+      description(requestBody.description),
+      renderContent(requestBody.content)];
+  }
+
+  function renderResponses(responses, produces) {
+    var codes = Object.keys(responses || {});
+    if (!codes.length) return null;
+    return [el("h5", null, "Responses"), codes.map(function (code) {
+      var response = deref(responses[code]) || {};
+      var kind = /^[23]/.test(code) ? "ok" : /^[45]/.test(code) ? "error" : "";
+      var headers = Object.keys(response.headers || {}).map(function (name) {
+        var header = deref(response.headers[name]) || {};
+        return el("tr", null, el("td", { class: "name" }, name),
+          el("td", null, typeNode(header.schema || header)),
+          el("td", null, description(header.description)));
+      });
+      var content = response.content;
+      if (swagger2 && response.schema) {
+        content = {};
+        content[(produces || ["application/json"])[0]] = { schema: response.schema, example: (response.examples || {})[(produces || [])[0]] };
+      }
+      return el("div", { class: "response" },
+        el("div", null, el("span", { class: "code " + kind }, code), response.description || ""),
+        headers.length ? el("table", null, el("tbody", null, headers)) : null,
+        renderContent(content),
+        response.links ? el("div", { class: "muted" }, "links: " + Object.keys(response.links).join(", ")) : null);
+    })];
+  }
+
+  function renderSecurity(requirements) {
+    if (!requirements) return null;
+    if (!requirements.length) return [el("h5", null, "Authorization"), el("div", { class: "muted" }, "none")];
+    return [el("h5", null, "Authorization"), requirements.map(function (requirement) {
+      var names = Object.keys(requirement);
+      if (!names.length) return el("div", { class: "muted" }, "optional");
+      return el("div", null, names.map(function (name, i) {
+        var scopes = requirement[name] || [];
+        return [i ? " + " : "", el("a", { href: "#" + anchor("security", name) }, name),
+          scopes.length ? el("span", { class: "muted" }, " (" + scopes.join(", ") + ")") : null];
+      }));
+    })];
+  }
+
+  function renderOperation(entry) {
+    var operation = entry.operation;
+    var node = el("details", {
+      class: "operation" + (operation.deprecated ? " deprecated" : ""),
+      id: operationId(entry),
+      "data-filter": (entry.method + " " + entry.path + " " + (operation.summary || "") + " " + (operation.operationId || "")).toLowerCase()
+    });
+    var built = false;
+    append(node, el("summary", null,
+      el("span", { class: "method " + entry.method }, entry.method),
+      el("span", { class: "path" }, entry.path),
+      operation.summary ? el("span", { class: "muted" }, operation.summary) : null,
+      operation.deprecated ? el("span", { class: "badge warn" }, "deprecated") : null));
+
+    // the body of an operation is only built once it is opened, large specs stay fast
+    node.addEventListener("toggle", function () {
+      if (built || !node.open) return;
+      built = true;
+      append(node, el("div", { class: "body" },
+        operation.operationId ? el("div", { class: "muted" }, "operationId: ", el("code", null, operation.operationId)) : null,
+        description(operation.description),
+        renderSecurity(operation.security || (entry.webhook ? null : spec.security)),
+        renderParameters(entry.parameters),
+        renderRequestBody(entry),
+        renderResponses(operation.responses, operation.produces || spec.produces),
+        operation.callbacks ? [el("h5", null, "Callbacks"), Object.keys(operation.callbacks).map(function (name) {
+          var callback = deref(operation.callbacks[name]) || {};
+          return el("div", null, el("strong", null, name), " ",
+            Object.keys(callback).map(function (expression) { return el("code", null, expression); }));
+        })] : null,
+        operation.externalDocs ? el("p", null, el("a", { href: operation.externalDocs.url }, operation.externalDocs.description || operation.externalDocs.url)) : null));
+    });
+    return node;
+  }
+
+  // groups operations by their first tag, in the order the tags are declared
+  function groupByTag(operations) {
+    var groups = [];
+    var byName = {};
+    function group(name) {
+      if (!byName[name]) {
+        var tag = (spec.tags || []).filter(function (t) { return t.name === name; })[0] || { name: name };
+        byName[name] = { tag: tag, operations: [] };
+        groups.push(byName[name]);
+      }
+      return byName[name];
+    }
+    (spec.tags || []).forEach(function (tag) { group(tag.name); });
+    operations.forEach(function (entry) {
+      var tags = entry.operation.tags || [];
+      group(tags.length ? tags[0] : "default").operations.push(entry);
+    });
+    return groups.filter(function (g) { return g.operations.length; });
+  }
+
+  // sections
+
+  function renderInfo() {
+    var info = spec.info || {};
+    var servers = swagger2
+      ? (spec.host ? (spec.schemes || ["https"]).map(function (scheme) { return { url: scheme + "://" + spec.host + (spec.basePath || "") }; }) : [])
+      : (spec.servers || []);
+    var contact = info.contact || {};
+    return el("section", { id: "info" },
+      el("h1", null, info.title || "API documentation", info.version ? el("span", { class: "version" }, info.version) : null),
+      el("div", { class: "muted" }, swagger2 ? "Swagger " + spec.swagger : "OpenAPI " + (spec.openapi || "")),
+      info.summary ? el("p", null, info.summary) : null,
+      description(info.description),
+      contact.name || contact.email || contact.url ? el("p", null, "Contact: ",
+        contact.url ? el("a", { href: contact.url }, contact.name || contact.url) : contact.name || "",
+        contact.email ? [" ", el("a", { href: "mailto:" + contact.email }, contact.email)] : null) : null,
+      info.license ? el("p", null, "License: ", info.license.url ? el("a", { href: info.license.url }, info.license.name) : info.license.name) : null,
+      servers.length ? [el("h5", null, "Servers"), el("table", null, el("tbody", null, servers.map(function (server) {
+        var variables = Object.keys(server.variables || {}).map(function (name) {
+          return name + " = " + server.variables[name].default;
+        });
+        return el("tr", null, el("td", { class: "name" }, server.url),
+          el("td", null, server.description || "", variables.length ? el("div", { class: "muted" }, variables.join(", ")) : null));
+      })))] : null);
+  }
+
+  function renderSecuritySchemes() {
+    var schemes = swagger2 ? spec.securityDefinitions : (spec.components || {}).securitySchemes;
+    var names = Object.keys(schemes || {});
+    if (!names.length) return null;
+    return el("section", { id: "authentication" }, el("h2", null, "Authentication"), names.map(function (name) {
+      var scheme = deref(schemes[name]) || {};
+      var details = [];
+      if (scheme.scheme) details.push(scheme.scheme + (scheme.bearerFormat ? " (" + scheme.bearerFormat + ")" : ""));
+      if (scheme.in) details.push(scheme.name + " in " + scheme.in);
+      if (scheme.openIdConnectUrl) details.push(scheme.openIdConnectUrl);
+      var flows = scheme.flows || (scheme.flow ? single(scheme) : {});
+      return el("div", { id: anchor("security", name), class: "response" },
+        el("div", null, el("strong", null, name), " ", el("span", { class: "badge" }, scheme.type), " ", details.join(", ")),
+        description(scheme.description),
+        Object.keys(flows).map(function (flow) {
+          var f = flows[flow] || {};
+          var scopes = Object.keys(f.scopes || {});
+          return el("div", { class: "schema" },
+            el("div", null, el("code", null, flow)),
+            f.authorizationUrl ? el("div", { class: "muted" }, "authorization: " + f.authorizationUrl) : null,
+            f.tokenUrl ? el("div", { class: "muted" }, "token: " + f.tokenUrl) : null,
+            scopes.length ? el("table", null, el("tbody", null, scopes.map(function (scope) {
+              return el("tr", null, el("td", { class: "name" }, scope), el("td", null, f.scopes[scope]));
+            }))) : null);
+        }));
+    }));
+  }
+
+  // swagger 2.0 describes a single flow on the scheme itself
+  function single(scheme) {
+    var flows = {};
+    flows[scheme.flow] = { authorizationUrl: scheme.authorizationUrl, tokenUrl: scheme.tokenUrl, scopes: scheme.scopes };
+    return flows;
+  }
+
+  function renderSchemas() {
+    var schemas = swagger2 ? spec.definitions : (spec.components || {}).schemas;
+    var names = Object.keys(schemas || {});
+    if (!names.length) return null;
+    return el("section", { id: "schemas" }, el("h2", null, "Schemas"), names.map(function (name) {
+      return el("div", { id: anchor("schema", name), class: "response" },
+        el("h3", null, name),
+        renderSchema(schemas[name], 0, [(swagger2 ? "#/definitions/" : "#/components/schemas/") + name]));
+    }));
+  }
+
+  function navLink(entry) {
+    var operation = entry.operation;
+    return el("a", {
+      href: "#" + operationId(entry),
+      "data-filter": (entry.method + " " + entry.path + " " + (operation.summary || "") + " " + (operation.operationId || "")).toLowerCase()
+    }, el("span", { class: "method " + entry.method }, entry.method), el("span", null, entry.path));
+  }
+
+  function render() {
+    var content = document.getElementById("content");
+    var nav = document.getElementById("nav");
+    var groups = groupByTag(collectOperations(spec.paths, false));
+    var webhooks = collectOperations(spec.webhooks || spec["x-webhooks"], true);
+
+    append(content, renderInfo(), renderSecuritySchemes());
+    append(nav, el("h4", null, el("a", { href: "#info" }, "Overview")));
+    groups.forEach(function (group) {
+      var id = anchor("tag", group.tag.name);
+      append(content, el("section", { id: id },
+        el("h2", null, group.tag.name),
+        description(group.tag.description),
+        group.operations.map(renderOperation)));
+      append(nav, el("h4", null, group.tag.name), group.operations.map(navLink));
+    });
+    if (webhooks.length) {
+      append(content, el("section", { id: "webhooks" }, el("h2", null, "Webhooks"), webhooks.map(renderOperation)));
+      append(nav, el("h4", null, "Webhooks"), webhooks.map(navLink));
+    }
+    var schemas = renderSchemas();
+    if (schemas) {
+      append(content, schemas);
+      append(nav, el("h4", null, el("a", { href: "#schemas" }, "Schemas")));
+    }
+  }
+
+  // opens the operation a link points at
+  function openTarget() {
+    var id = decodeURIComponent(location.hash.slice(1));
+    var target = id && document.getElementById(id);
+    if (target && target.tagName === "DETAILS") {
+      target.open = true;
+      target.scrollIntoView();
+    }
+  }
+
+  function filter(query) {
+    query = query.trim().toLowerCase();
+    document.querySelectorAll("[data-filter]").forEach(function (node) {
+      node.style.display = !query || node.getAttribute("data-filter").indexOf(query) >= 0 ? "" : "none";
+    });
+  }
+
+  render();
+  window.addEventListener("hashchange", openTarget);
+  document.getElementById("filter").addEventListener("input", function (event) { filter(event.target.value); });
+  openTarget();
+})();
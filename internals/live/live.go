@@ -42,6 +42,11 @@ func openBrowser(url string) error {
 //go:embed index.html
 var index string
 
+// redirect target for the OAuth2 authorize dialog of Swagger UI
+//
+//go:embed oauth2-redirect.html
+var oauth2Redirect string
+
 func SwaggerLive(fileName string) {
 	msg := make(chan []byte)
 
@@ -150,6 +155,9 @@ func SwaggerLive(fileName string) {
 	}
 
 	portStr := strconv.Itoa(port)
+	http.HandleFunc("/oauth2-redirect.html", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(oauth2Redirect))
+	})
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		body := fmt.Sprintf(index, portStr)
 		_, _ = w.Write([]byte(body))
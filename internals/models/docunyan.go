@@ -1,5 +1,14 @@
 package models
 
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
 var (
 	StructSchemas = map[string]map[string]interface{}{}
 )
@@ -11,7 +20,113 @@ type Authorization struct {
 	In     string   `yaml:"in,omitempty"`
 }
 
+// single OAuth2 flow with the scopes it can grant
+type OAuthFlow struct {
+	AuthorizationURL string            `yaml:"authorizationUrl,omitempty"` // implicit, authorizationCode
+	TokenURL         string            `yaml:"tokenUrl,omitempty"`         // password, clientCredentials, authorizationCode
+	RefreshURL       string            `yaml:"refreshUrl,omitempty"`
+	Scopes           map[string]string `yaml:"scopes"` // scope name -> description
+}
+
+type OAuthFlows struct {
+	AuthorizationCode *OAuthFlow `yaml:"authorizationCode,omitempty"`
+	ClientCredentials *OAuthFlow `yaml:"clientCredentials,omitempty"`
+	Password          *OAuthFlow `yaml:"password,omitempty"`
+	Implicit          *OAuthFlow `yaml:"implicit,omitempty"`
+}
+
+// named security scheme declared under securitySchemes
+type SecurityScheme struct {
+	Type             string      `yaml:"type"` // http, apiKey, oauth2, openIdConnect or mutualTLS
+	Description      string      `yaml:"description,omitempty"`
+	Scheme           string      `yaml:"scheme,omitempty"`           // http: bearer, basic, digest...
+	BearerFormat     string      `yaml:"bearerFormat,omitempty"`     // http bearer: e.g. JWT
+	Name             string      `yaml:"name,omitempty"`             // apiKey: header, query or cookie name
+	In               string      `yaml:"in,omitempty"`               // apiKey: header, query or cookie
+	Flows            *OAuthFlows `yaml:"flows,omitempty"`            // oauth2
+	OpenIDConnectURL string      `yaml:"openIdConnectUrl,omitempty"` // openIdConnect discovery document
+}
+
+// declared scopes across every flow of an oauth2 scheme
+func (s SecurityScheme) HasScope(scope string) bool {
+	if s.Flows == nil {
+		return false
+	}
+	for _, flow := range []*OAuthFlow{s.Flows.AuthorizationCode, s.Flows.ClientCredentials, s.Flows.Password, s.Flows.Implicit} {
+		if flow == nil {
+			continue
+		}
+		if _, ok := flow.Scopes[scope]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// scheme required by a security requirement, written as a name or as {scheme: [scopes]}
+type SchemeRequirement struct {
+	Name   string
+	Scopes []string
+}
+
+func (r *SchemeRequirement) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		r.Name = name
+		return nil
+	}
+
+	var scoped map[string][]string
+	if err := unmarshal(&scoped); err != nil {
+		return err
+	}
+	if len(scoped) != 1 {
+		return fmt.Errorf("scheme requirement must name exactly one scheme, got %d", len(scoped))
+	}
+	for name, scopes := range scoped {
+		r.Name = name
+		r.Scopes = scopes
+	}
+	return nil
+}
+
+// one alternative of a security list, every scheme in it is required together.
+// written as a name, {scheme: [scopes], ...} or a list of those; empty allows anonymous access
+type SecurityRequirement []SchemeRequirement
+
+func (r *SecurityRequirement) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*r = SecurityRequirement{{Name: name}}
+		return nil
+	}
+
+	var all []SchemeRequirement
+	if err := unmarshal(&all); err == nil {
+		*r = all
+		return nil
+	}
+
+	var scoped map[string][]string
+	if err := unmarshal(&scoped); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(scoped))
+	for name := range scoped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	*r = SecurityRequirement{}
+	for _, name := range names {
+		*r = append(*r, SchemeRequirement{Name: name, Scopes: scoped[name]})
+	}
+	return nil
+}
+
 type Parameter struct {
+	Ref         string `yaml:"$ref,omitempty"` // name of a parameter in components.parameters
 	Name        string `yaml:"name,omitempty"`
 	In          string `yaml:"in,omitempty"`
 	Required    bool   `yaml:"required,omitempty"`
@@ -19,32 +134,273 @@ type Parameter struct {
 	Description string `yaml:"description,omitempty"`
 }
 
+// named example, either inline (value) or loaded from a JSON file (file)
+type Example struct {
+	Summary       string      `yaml:"summary,omitempty"`
+	Description   string      `yaml:"description,omitempty"`
+	Value         interface{} `yaml:"value,omitempty"`
+	File          string      `yaml:"file,omitempty"`
+	ExternalValue string      `yaml:"externalValue,omitempty"`
+}
+
+// accepts either a path to a JSON file or a full example object
+func (e *Example) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var file string
+	if err := unmarshal(&file); err == nil {
+		e.File = file
+		return nil
+	}
+
+	type rawExample Example
+	var raw rawExample
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*e = Example(raw)
+	return nil
+}
+
+type RequestBody struct {
+	Ref         string             `yaml:"$ref,omitempty"` // name of a request body in components.requestBodies
+	Schema      string             `yaml:"schema,omitempty"`
+	Description string             `yaml:"description,omitempty"`
+	Required    *bool              `yaml:"required,omitempty"`
+	Example     interface{}        `yaml:"example,omitempty"` // inline value or path to a JSON file
+	Examples    map[string]Example `yaml:"examples,omitempty"`
+}
+
+// accepts either a schema name or a full request body object
+func (r *RequestBody) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var schema string
+	if err := unmarshal(&schema); err == nil {
+		r.Schema = schema
+		return nil
+	}
+
+	type rawRequestBody RequestBody
+	var raw rawRequestBody
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*r = RequestBody(raw)
+	return nil
+}
+
+type Header struct {
+	Ref         string `yaml:"$ref,omitempty"` // name of a header in components.headers
+	Description string `yaml:"description,omitempty"`
+	Type        string `yaml:"type,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+}
+
 type Response struct {
-	Description string `yaml:"description"`
-	Schema      string `yaml:"schema"`
+	Ref         string             `yaml:"$ref,omitempty"` // name of a response in components.responses
+	Description string             `yaml:"description"`
+	Schema      string             `yaml:"schema"`
+	Headers     map[string]Header  `yaml:"headers,omitempty"`
+	Example     interface{}        `yaml:"example,omitempty"` // inline value or path to a JSON file
+	Examples    map[string]Example `yaml:"examples,omitempty"`
+	Links       map[string]Link    `yaml:"links,omitempty"`
+}
+
+// describes how values of a response can be used as input of another operation
+type Link struct {
+	OperationID  string            `yaml:"operationId,omitempty"`
+	OperationRef string            `yaml:"operationRef,omitempty"` // "GET /orders/:id" or a JSON pointer into the spec
+	Description  string            `yaml:"description,omitempty"`
+	Parameters   map[string]string `yaml:"parameters,omitempty"` // response body field or runtime expression
+	RequestBody  string            `yaml:"requestBody,omitempty"`
+}
+
+type ExternalDocs struct {
+	Description string `yaml:"description,omitempty"`
+	URL         string `yaml:"url"`
 }
 
 type EndpointDetail struct {
-	Query         map[string]string   `yaml:"query,omitempty"`
-	Summary       string              `yaml:"summary,omitempty"`
-	Tags          []string            `yaml:"tags,omitempty"`
-	RequestBody   string              `yaml:"requestBody,omitempty"`
-	Parameter     interface{}         `yaml:"parameter,omitempty"` // Can be string or complex object
-	Parameters    []Parameter         `yaml:"parameters,omitempty"`
-	Responses     map[string]Response `yaml:"responses,omitempty"`
-	Authorization bool                `yaml:"authorization,omitempty"`
+	Query         QueryParams           `yaml:"query,omitempty"`
+	OperationID   string                `yaml:"operationId,omitempty"` // generated from method + path when omitted
+	Summary       string                `yaml:"summary,omitempty"`
+	Description   string                `yaml:"description,omitempty"`
+	Deprecated    bool                  `yaml:"deprecated,omitempty"`
+	ExternalDocs  *ExternalDocs         `yaml:"externalDocs,omitempty"`
+	Tags          []string              `yaml:"tags,omitempty"`
+	RequestBody   *RequestBody          `yaml:"requestBody,omitempty"` // Can be schema name or complex object
+	Parameter     interface{}           `yaml:"parameter,omitempty"`   // Can be string or complex object
+	Parameters    []Parameter           `yaml:"parameters,omitempty"`
+	Responses     map[string]Response   `yaml:"responses,omitempty"`
+	Authorization bool                  `yaml:"authorization,omitempty"` // legacy: attach every security scheme
+	Security      []SecurityRequirement `yaml:"security,omitempty"`      // security schemes (and scopes) that apply
+	Callbacks     map[string]Callback   `yaml:"callbacks,omitempty"`     // outgoing requests triggered by this operation
+}
+
+// outgoing requests of a callback keyed by runtime expression, e.g. {$request.body#/callbackUrl}
+type Callback map[string]PathItem
+
+// query parameter declared as name: type
+type QueryParam struct {
+	Name string
+	Type string
+}
+
+// query parameters in declaration order
+type QueryParams []QueryParam
+
+func (q *QueryParams) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: query must be a mapping of name: type", value.Line)
+	}
+
+	params := QueryParams{}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		var paramType string
+		if err := value.Content[i+1].Decode(&paramType); err != nil {
+			return err
+		}
+		params = append(params, QueryParam{Name: value.Content[i].Value, Type: paramType})
+	}
+	*q = params
+	return nil
+}
+
+// operations of a single path plus the parameters shared by all of them
+type PathItem struct {
+	Parameters []Parameter
+	Operations map[string]EndpointDetail
+}
+
+// a path entry is either the shared parameter list or an operation keyed by HTTP method
+type pathItemEntry struct {
+	parameters []Parameter
+	operation  *EndpointDetail
+}
+
+func (e *pathItemEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var parameters []Parameter
+	if err := unmarshal(&parameters); err == nil {
+		e.parameters = parameters
+		return nil
+	}
+
+	var operation EndpointDetail
+	if err := unmarshal(&operation); err != nil {
+		return err
+	}
+	e.operation = &operation
+	return nil
+}
+
+func (p *PathItem) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var entries map[string]yaml.Node
+	if err := unmarshal(&entries); err != nil {
+		return err
+	}
+
+	p.Operations = map[string]EndpointDetail{}
+	for key, node := range entries {
+		// other keys are typos such as gett, rejected as unknown keys before decoding
+		// or only reported in lenient mode
+		if key != "parameters" && !utils.IsHTTPMethod(key) {
+			continue
+		}
+
+		var entry pathItemEntry
+		if err := node.Decode(&entry); err != nil {
+			return err
+		}
+		if key == "parameters" && entry.operation == nil {
+			p.Parameters = entry.parameters
+			continue
+		}
+		if entry.operation == nil {
+			if entry.parameters != nil {
+				return fmt.Errorf("%s: expected an operation object", key)
+			}
+			entry.operation = &EndpointDetail{}
+		}
+		p.Operations[key] = *entry.operation
+	}
+	return nil
+}
+
+// tag declaration, the order of the tags list is the order shown in the docs
+type Tag struct {
+	Name         string        `yaml:"name"`
+	Description  string        `yaml:"description,omitempty"`
+	ExternalDocs *ExternalDocs `yaml:"externalDocs,omitempty"`
+}
+
+// named group of tags, emitted as x-tagGroups
+type TagGroup struct {
+	Name string   `yaml:"name"`
+	Tags []string `yaml:"tags"`
+}
+
+// reusable objects referenced by name from endpoints
+type Components struct {
+	Parameters    map[string]Parameter   `yaml:"parameters,omitempty"`
+	Responses     map[string]Response    `yaml:"responses,omitempty"`
+	Headers       map[string]Header      `yaml:"headers,omitempty"`
+	RequestBodies map[string]RequestBody `yaml:"requestBodies,omitempty"`
+}
+
+type Contact struct {
+	Name  string `yaml:"name,omitempty"`
+	URL   string `yaml:"url,omitempty"`
+	Email string `yaml:"email,omitempty"`
+}
+
+type License struct {
+	Name       string `yaml:"name"`
+	URL        string `yaml:"url,omitempty"`
+	Identifier string `yaml:"identifier,omitempty"` // SPDX expression, OpenAPI 3.1 only
+}
+
+// logo shown by ReDoc, emitted as info.x-logo
+type Logo struct {
+	URL             string `yaml:"url"`
+	AltText         string `yaml:"altText,omitempty"`
+	BackgroundColor string `yaml:"backgroundColor,omitempty"`
+	Href            string `yaml:"href,omitempty"`
+}
+
+type Info struct {
+	Title          string   `yaml:"title"`
+	Summary        string   `yaml:"summary,omitempty"` // OpenAPI 3.1 only
+	Version        string   `yaml:"version"`
+	Description    string   `yaml:"description,omitempty"`
+	TermsOfService string   `yaml:"termsOfService,omitempty"`
+	Contact        *Contact `yaml:"contact,omitempty"`
+	License        *License `yaml:"license,omitempty"`
+	Logo           *Logo    `yaml:"x-logo,omitempty"`
+}
+
+// substitution value for a {variable} in a server URL
+type ServerVariable struct {
+	Enum        []string `yaml:"enum,omitempty"`
+	Default     string   `yaml:"default"`
+	Description string   `yaml:"description,omitempty"`
+}
+
+type Server struct {
+	URL         string                    `yaml:"url"`
+	Description string                    `yaml:"description,omitempty"`
+	Variables   map[string]ServerVariable `yaml:"variables,omitempty"`
 }
 
 type DocunyanYAML struct {
-	Info struct {
-		Title       string `yaml:"title"`
-		Version     string `yaml:"version"`
-		Description string `yaml:"description,omitempty"`
-	} `yaml:"info"`
-	Servers []struct {
-		URL         string `yaml:"url"`
-		Description string `yaml:"description,omitempty"`
-	} `yaml:"servers"`
-	Paths         map[string]map[string]EndpointDetail `yaml:"paths"`
-	Authorization *Authorization                       `yaml:"authorization,omitempty"`
+	Info          Info                `yaml:"info"`
+	Servers       []Server            `yaml:"servers"`
+	Tags          []Tag               `yaml:"tags,omitempty"`
+	TagGroups     []TagGroup          `yaml:"tagGroups,omitempty"`
+	Paths         map[string]PathItem `yaml:"paths"`
+	Webhooks      map[string]PathItem `yaml:"webhooks,omitempty"` // requests the API sends to its consumers
+	Components    *Components         `yaml:"components,omitempty"`
+	Authorization *Authorization      `yaml:"authorization,omitempty"` // legacy single block, still supported
+
+	SecuritySchemes map[string]SecurityScheme `yaml:"securitySchemes,omitempty"`
+	Security        []SecurityRequirement     `yaml:"security,omitempty"` // default for endpoints without security
+
+	// directory of the config file, used to resolve external example files
+	BaseDir string `yaml:"-"`
 }
@@ -0,0 +1,356 @@
+// Package openapi is a typed model of the generated OpenAPI document.
+// field order follows the order of the specification, so encoding a document
+// gives a readable and stable key order
+package openapi
+
+import (
+	"encoding/json"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+type Document struct {
+	OpenAPI   string                `json:"openapi"`
+	Info      Info                  `json:"info"`
+	Servers   []Server              `json:"servers,omitempty"`
+	Tags      []Tag                 `json:"tags,omitempty"`
+	TagGroups []TagGroup            `json:"x-tagGroups,omitempty"`
+	Security  []SecurityRequirement `json:"security,omitempty"`
+	Paths     map[string]*PathItem  `json:"paths"`
+	Webhooks  map[string]*PathItem  `json:"webhooks,omitempty"`
+	// webhooks of an OpenAPI 3.0 document, carried as an extension
+	XWebhooks  map[string]*PathItem `json:"x-webhooks,omitempty"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+type Info struct {
+	Title          string   `json:"title"`
+	Summary        string   `json:"summary,omitempty"`
+	Description    string   `json:"description"`
+	TermsOfService string   `json:"termsOfService,omitempty"`
+	Contact        *Contact `json:"contact,omitempty"`
+	License        *License `json:"license,omitempty"`
+	Version        string   `json:"version"`
+	Logo           *Logo    `json:"x-logo,omitempty"`
+}
+
+type Contact struct {
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type License struct {
+	Name       string `json:"name"`
+	Identifier string `json:"identifier,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+type Logo struct {
+	URL             string `json:"url"`
+	AltText         string `json:"altText,omitempty"`
+	BackgroundColor string `json:"backgroundColor,omitempty"`
+	Href            string `json:"href,omitempty"`
+}
+
+type Server struct {
+	URL         string                    `json:"url"`
+	Description string                    `json:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty"`
+}
+
+type ServerVariable struct {
+	Enum        []string `json:"enum,omitempty"`
+	Default     string   `json:"default"`
+	Description string   `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+}
+
+type TagGroup struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+type ExternalDocs struct {
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+}
+
+// scheme names mapped to the scopes they need, all of them are required together
+type SecurityRequirement map[string][]string
+
+// operations of a path in the order of the specification
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters,omitempty"`
+	Get        *Operation   `json:"get,omitempty"`
+	Put        *Operation   `json:"put,omitempty"`
+	Post       *Operation   `json:"post,omitempty"`
+	Delete     *Operation   `json:"delete,omitempty"`
+	Options    *Operation   `json:"options,omitempty"`
+	Head       *Operation   `json:"head,omitempty"`
+	Patch      *Operation   `json:"patch,omitempty"`
+	Trace      *Operation   `json:"trace,omitempty"`
+}
+
+// sets the operation of a lowercase HTTP method, returns false for unknown methods
+func (p *PathItem) SetOperation(method string, operation *Operation) bool {
+	switch method {
+	case "get":
+		p.Get = operation
+	case "put":
+		p.Put = operation
+	case "post":
+		p.Post = operation
+	case "delete":
+		p.Delete = operation
+	case "options":
+		p.Options = operation
+	case "head":
+		p.Head = operation
+	case "patch":
+		p.Patch = operation
+	case "trace":
+		p.Trace = operation
+	default:
+		return false
+	}
+	return true
+}
+
+// operations keyed by lowercase method, in the order of the specification
+func (p *PathItem) Operations() ([]string, []*Operation) {
+	methods := []string{}
+	operations := []*Operation{}
+	for _, entry := range []struct {
+		method    string
+		operation *Operation
+	}{
+		{"get", p.Get}, {"put", p.Put}, {"post", p.Post}, {"delete", p.Delete},
+		{"options", p.Options}, {"head", p.Head}, {"patch", p.Patch}, {"trace", p.Trace},
+	} {
+		if entry.operation != nil {
+			methods = append(methods, entry.method)
+			operations = append(operations, entry.operation)
+		}
+	}
+	return methods, operations
+}
+
+type Operation struct {
+	Tags         []string               `json:"tags,omitempty"`
+	Summary      string                 `json:"summary"`
+	Description  string                 `json:"description,omitempty"`
+	ExternalDocs *ExternalDocs          `json:"externalDocs,omitempty"`
+	OperationID  string                 `json:"operationId,omitempty"`
+	Parameters   []*Parameter           `json:"parameters,omitempty"`
+	RequestBody  *RequestBody           `json:"requestBody,omitempty"`
+	Responses    map[string]*Response   `json:"responses"`
+	Callbacks    map[string]Callback    `json:"callbacks,omitempty"`
+	Deprecated   bool                   `json:"deprecated,omitempty"`
+	Security     *[]SecurityRequirement `json:"security,omitempty"` // nil inherits, an empty list disables security
+}
+
+// outgoing requests keyed by runtime expression
+type Callback map[string]*PathItem
+
+// parameter, or a reference to one when Ref is set
+type Parameter struct {
+	Ref         string  `json:"-"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	if p.Ref != "" {
+		return json.Marshal(Reference{Ref: p.Ref})
+	}
+	type parameter Parameter
+	return json.Marshal(parameter(p))
+}
+
+// request body, or a reference to one when Ref is set
+type RequestBody struct {
+	Ref         string                `json:"-"`
+	Description string                `json:"description,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+	Required    bool                  `json:"required"`
+}
+
+func (r RequestBody) MarshalJSON() ([]byte, error) {
+	if r.Ref != "" {
+		return json.Marshal(Reference{Ref: r.Ref})
+	}
+	type requestBody RequestBody
+	return json.Marshal(requestBody(r))
+}
+
+type MediaType struct {
+	Schema   *Schema             `json:"schema,omitempty"`
+	Example  interface{}         `json:"example,omitempty"`
+	Examples map[string]*Example `json:"examples,omitempty"`
+}
+
+type Example struct {
+	Summary       string      `json:"summary,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	Value         interface{} `json:"value,omitempty"`
+	ExternalValue string      `json:"externalValue,omitempty"`
+}
+
+// response, or a reference to one when Ref is set
+type Response struct {
+	Ref         string                `json:"-"`
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+	Links       map[string]*Link      `json:"links,omitempty"`
+}
+
+func (r Response) MarshalJSON() ([]byte, error) {
+	if r.Ref != "" {
+		return json.Marshal(Reference{Ref: r.Ref})
+	}
+	type response Response
+	return json.Marshal(response(r))
+}
+
+// response header, or a reference to one when Ref is set
+type Header struct {
+	Ref         string  `json:"-"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+func (h Header) MarshalJSON() ([]byte, error) {
+	if h.Ref != "" {
+		return json.Marshal(Reference{Ref: h.Ref})
+	}
+	type header Header
+	return json.Marshal(header(h))
+}
+
+type Link struct {
+	OperationRef string                 `json:"operationRef,omitempty"`
+	OperationID  string                 `json:"operationId,omitempty"`
+	Parameters   map[string]interface{} `json:"parameters,omitempty"`
+	RequestBody  interface{}            `json:"requestBody,omitempty"`
+	Description  string                 `json:"description,omitempty"`
+}
+
+type Reference struct {
+	Ref string `json:"$ref"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Responses       map[string]*Response       `json:"responses,omitempty"`
+	Parameters      map[string]*Parameter      `json:"parameters,omitempty"`
+	RequestBodies   map[string]*RequestBody    `json:"requestBodies,omitempty"`
+	Headers         map[string]*Header         `json:"headers,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type             string      `json:"type"`
+	Description      string      `json:"description,omitempty"`
+	Name             string      `json:"name,omitempty"`
+	In               string      `json:"in,omitempty"`
+	Scheme           string      `json:"scheme,omitempty"`
+	BearerFormat     string      `json:"bearerFormat,omitempty"`
+	Flows            *OAuthFlows `json:"flows,omitempty"`
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"`
+}
+
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+}
+
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// schema object, a subset of JSON Schema as used by OpenAPI 3.0, 3.1 and Swagger 2.0
+type Schema struct {
+	Ref                  string        `json:"$ref,omitempty"`
+	Type                 SchemaType    `json:"type,omitempty"`
+	Format               string        `json:"format,omitempty"`
+	Description          string        `json:"description,omitempty"`
+	Nullable             bool          `json:"nullable,omitempty"`   // OpenAPI 3.0 only
+	XNullable            bool          `json:"x-nullable,omitempty"` // Swagger 2.0 only
+	Properties           *Properties   `json:"properties,omitempty"`
+	Required             []string      `json:"required,omitempty"`
+	Items                *Schema       `json:"items,omitempty"`
+	AdditionalProperties *Schema       `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema     `json:"allOf,omitempty"`
+	AnyOf                []*Schema     `json:"anyOf,omitempty"`
+	OneOf                []*Schema     `json:"oneOf,omitempty"`
+	Enum                 []interface{} `json:"enum,omitempty"`
+	Const                interface{}   `json:"const,omitempty"`
+	Default              interface{}   `json:"default,omitempty"`
+	Example              interface{}   `json:"example,omitempty"`
+	Examples             []interface{} `json:"examples,omitempty"` // OpenAPI 3.1 only
+}
+
+// schema type, a single name or a list of names such as ["string", "null"]
+type SchemaType []string
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// schema properties in declaration order
+type Properties struct {
+	names   []string
+	schemas map[string]*Schema
+}
+
+func NewProperties() *Properties {
+	return &Properties{schemas: map[string]*Schema{}}
+}
+
+// sets a property, existing names keep their position
+func (p *Properties) Set(name string, schema *Schema) {
+	if _, exists := p.schemas[name]; !exists {
+		p.names = append(p.names, name)
+	}
+	p.schemas[name] = schema
+}
+
+func (p *Properties) Get(name string) (*Schema, bool) {
+	schema, ok := p.schemas[name]
+	return schema, ok
+}
+
+func (p *Properties) Names() []string {
+	return append([]string(nil), p.names...)
+}
+
+func (p *Properties) Len() int {
+	return len(p.names)
+}
+
+func (p *Properties) MarshalJSON() ([]byte, error) {
+	ordered := utils.NewOrderedMap()
+	for _, name := range p.names {
+		ordered.Set(name, p.schemas[name])
+	}
+	return ordered.MarshalJSON()
+}
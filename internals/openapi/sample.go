@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// builds an example value for a schema: explicit examples, defaults and enum values
+// first, placeholders by type and format otherwise. references are followed into the
+// component schemas of the document, recursive ones end with null
+func (d *Document) SampleValue(schema *Schema) interface{} {
+	return d.sample(schema, map[string]bool{})
+}
+
+func (d *Document) sample(schema *Schema, visiting map[string]bool) interface{} {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		target, ok := d.componentSchema(name)
+		if !ok || visiting[name] {
+			return nil
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+		return d.sample(target, visiting)
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case schema.Const != nil:
+		return schema.Const
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		// objects of every part are merged, e.g. embedded or nullable references
+		merged := utils.NewOrderedMap()
+		for _, part := range schema.AllOf {
+			value := d.sample(part, visiting)
+			object, ok := value.(*utils.OrderedMap)
+			if !ok {
+				return value
+			}
+			for _, key := range object.Keys() {
+				field, _ := object.Get(key)
+				merged.Set(key, field)
+			}
+		}
+		return merged
+	case len(schema.OneOf) > 0:
+		return d.sample(schema.OneOf[0], visiting)
+	case len(schema.AnyOf) > 0:
+		return d.sample(schema.AnyOf[0], visiting)
+	}
+
+	switch schemaTypeName(schema.Type) {
+	case "object":
+		object := utils.NewOrderedMap()
+		if schema.Properties != nil {
+			for _, name := range schema.Properties.Names() {
+				property, _ := schema.Properties.Get(name)
+				object.Set(name, d.sample(property, visiting))
+			}
+		}
+		return object
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{d.sample(schema.Items, visiting)}
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return true
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	}
+	return nil
+}
+
+func (d *Document) componentSchema(name string) (*Schema, bool) {
+	if d.Components == nil {
+		return nil, false
+	}
+	schema, ok := d.Components.Schemas[name]
+	return schema, ok
+}
+
+// first non-null type name, e.g. ["string", "null"] -> string
+func schemaTypeName(types SchemaType) string {
+	for _, name := range types {
+		if name != "null" {
+			return name
+		}
+	}
+	return ""
+}
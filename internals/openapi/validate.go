@@ -0,0 +1,329 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// structural problem of a document, Path holds the keys leading to the offending
+// value, e.g. ["paths", "/orders/{id}", "get", "responses", "200"]
+type Problem struct {
+	Path    []string
+	Message string
+}
+
+func (p Problem) Error() string {
+	return strings.Join(p.Path, ".") + ": " + p.Message
+}
+
+var pathTemplateParam = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// checks that references resolve, required fields are set, path template parameters
+// are declared and operationIds are unique. problems are sorted by location
+func Validate(d *Document) []Problem {
+	v := &validator{doc: d, operationIDs: map[string]string{}}
+	v.validate()
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return strings.Join(v.problems[i].Path, "\x00") < strings.Join(v.problems[j].Path, "\x00")
+	})
+	return v.problems
+}
+
+type validator struct {
+	doc          *Document
+	operationIDs map[string]string // operationId -> location of its first use
+	problems     []Problem
+}
+
+func (v *validator) report(path []string, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{
+		Path:    append([]string(nil), path...),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// appends keys to a copy of path, so sibling locations never share a backing array
+func at(path []string, keys ...string) []string {
+	return append(append(make([]string, 0, len(path)+len(keys)), path...), keys...)
+}
+
+func (v *validator) validate() {
+	if v.doc.OpenAPI == "" {
+		v.report([]string{"openapi"}, "version is required")
+	}
+	if v.doc.Info.Title == "" {
+		v.report([]string{"info", "title"}, "title is required")
+	}
+	if v.doc.Info.Version == "" {
+		v.report([]string{"info", "version"}, "version is required")
+	}
+	if v.doc.Info.License != nil && v.doc.Info.License.Name == "" {
+		v.report([]string{"info", "license", "name"}, "license name is required")
+	}
+
+	for i, server := range v.doc.Servers {
+		if server.URL == "" {
+			v.report([]string{"servers", fmt.Sprint(i), "url"}, "server url is required")
+		}
+	}
+	for i, tag := range v.doc.Tags {
+		if tag.Name == "" {
+			v.report([]string{"tags", fmt.Sprint(i), "name"}, "tag name is required")
+		}
+	}
+
+	if v.doc.Paths == nil {
+		v.report([]string{"paths"}, "paths is required")
+	}
+	for _, path := range utils.SortedKeys(v.doc.Paths) {
+		v.pathItem([]string{"paths", path}, path, v.doc.Paths[path], true)
+	}
+	for _, name := range utils.SortedKeys(v.doc.Webhooks) {
+		v.pathItem([]string{"webhooks", name}, name, v.doc.Webhooks[name], false)
+	}
+	for _, name := range utils.SortedKeys(v.doc.XWebhooks) {
+		v.pathItem([]string{"x-webhooks", name}, name, v.doc.XWebhooks[name], false)
+	}
+
+	if c := v.doc.Components; c != nil {
+		for _, name := range utils.SortedKeys(c.Schemas) {
+			v.schema([]string{"components", "schemas", name}, c.Schemas[name])
+		}
+		for _, name := range utils.SortedKeys(c.Parameters) {
+			v.parameter([]string{"components", "parameters", name}, c.Parameters[name])
+		}
+		for _, name := range utils.SortedKeys(c.Headers) {
+			v.header([]string{"components", "headers", name}, c.Headers[name])
+		}
+		for _, name := range utils.SortedKeys(c.Responses) {
+			v.response([]string{"components", "responses", name}, c.Responses[name])
+		}
+		for _, name := range utils.SortedKeys(c.RequestBodies) {
+			v.requestBody([]string{"components", "requestBodies", name}, c.RequestBodies[name])
+		}
+	}
+}
+
+// template parameters are only checked for paths, webhook names and callback
+// expressions are not URL templates
+func (v *validator) pathItem(path []string, template string, item *PathItem, checkTemplate bool) {
+	for i, param := range item.Parameters {
+		v.parameter(at(path, "parameters", fmt.Sprint(i)), param)
+	}
+
+	methods, operations := item.Operations()
+	for i, method := range methods {
+		operation := operations[i]
+		location := at(path, method)
+		v.operation(location, operation)
+
+		if checkTemplate {
+			v.pathParameters(location, template, item.Parameters, operation.Parameters)
+		}
+	}
+}
+
+func (v *validator) operation(path []string, operation *Operation) {
+	if operation.OperationID != "" {
+		owner := strings.Join(path, ".")
+		if other, exists := v.operationIDs[operation.OperationID]; exists {
+			v.report(at(path, "operationId"), "operationId %q is already used by %s", operation.OperationID, other)
+		} else {
+			v.operationIDs[operation.OperationID] = owner
+		}
+	}
+
+	for i, param := range operation.Parameters {
+		v.parameter(at(path, "parameters", fmt.Sprint(i)), param)
+	}
+	if operation.RequestBody != nil {
+		v.requestBody(at(path, "requestBody"), operation.RequestBody)
+	}
+
+	if len(operation.Responses) == 0 {
+		v.report(at(path, "responses"), "at least one response is required")
+	}
+	for _, code := range utils.SortedKeys(operation.Responses) {
+		v.response(at(path, "responses", code), operation.Responses[code])
+	}
+
+	for _, name := range utils.SortedKeys(operation.Callbacks) {
+		for _, expression := range utils.SortedKeys(operation.Callbacks[name]) {
+			v.pathItem(at(path, "callbacks", name, expression), expression, operation.Callbacks[name][expression], false)
+		}
+	}
+}
+
+// every {name} of the path needs a required path parameter and every path parameter
+// needs a {name} in the path
+func (v *validator) pathParameters(path []string, template string, groups ...[]*Parameter) {
+	declared := map[string]bool{}
+	for _, params := range groups {
+		for _, param := range params {
+			param = v.resolveParameter(param)
+			if param == nil || param.In != "path" {
+				continue
+			}
+			declared[param.Name] = true
+
+			if !strings.Contains(template, "{"+param.Name+"}") {
+				v.report(at(path, "parameters"), "path parameter %q does not appear in %s", param.Name, template)
+			} else if !param.Required {
+				v.report(at(path, "parameters"), "path parameter %q must be required", param.Name)
+			}
+		}
+	}
+
+	for _, match := range pathTemplateParam.FindAllStringSubmatch(template, -1) {
+		if !declared[match[1]] {
+			v.report(path, "path parameter %q is not declared", match[1])
+		}
+	}
+}
+
+// follows a parameter reference, nil when it does not resolve
+func (v *validator) resolveParameter(param *Parameter) *Parameter {
+	if param.Ref == "" {
+		return param
+	}
+	name, ok := strings.CutPrefix(param.Ref, "#/components/parameters/")
+	if !ok || v.doc.Components == nil {
+		return nil
+	}
+	return v.doc.Components.Parameters[name]
+}
+
+func (v *validator) parameter(path []string, param *Parameter) {
+	if param.Ref != "" {
+		v.ref(path, param.Ref, "parameters")
+		return
+	}
+	if param.Name == "" {
+		v.report(at(path, "name"), "parameter name is required")
+	}
+	switch param.In {
+	case "path", "query", "header", "cookie":
+	case "":
+		v.report(at(path, "in"), "parameter location is required")
+	default:
+		v.report(at(path, "in"), "invalid parameter location %q, expected path, query, header or cookie", param.In)
+	}
+	v.schema(at(path, "schema"), param.Schema)
+}
+
+func (v *validator) header(path []string, header *Header) {
+	if header.Ref != "" {
+		v.ref(path, header.Ref, "headers")
+		return
+	}
+	v.schema(at(path, "schema"), header.Schema)
+}
+
+func (v *validator) requestBody(path []string, body *RequestBody) {
+	if body.Ref != "" {
+		v.ref(path, body.Ref, "requestBodies")
+		return
+	}
+	if len(body.Content) == 0 {
+		v.report(at(path, "content"), "request body content is required")
+	}
+	v.content(at(path, "content"), body.Content)
+}
+
+func (v *validator) response(path []string, resp *Response) {
+	if resp.Ref != "" {
+		v.ref(path, resp.Ref, "responses")
+		return
+	}
+	if resp.Description == "" {
+		v.report(at(path, "description"), "response description is required")
+	}
+	for _, name := range utils.SortedKeys(resp.Headers) {
+		v.header(at(path, "headers", name), resp.Headers[name])
+	}
+	v.content(at(path, "content"), resp.Content)
+	for _, name := range utils.SortedKeys(resp.Links) {
+		if link := resp.Links[name]; link.OperationID == "" && link.OperationRef == "" {
+			v.report(at(path, "links", name), "link requires operationId or operationRef")
+		}
+	}
+}
+
+func (v *validator) content(path []string, content map[string]*MediaType) {
+	for _, mime := range utils.SortedKeys(content) {
+		v.schema(at(path, mime, "schema"), content[mime].Schema)
+	}
+}
+
+func (v *validator) schema(path []string, schema *Schema) {
+	if schema == nil {
+		return
+	}
+	if schema.Ref != "" {
+		v.ref(path, schema.Ref, "schemas")
+	}
+
+	if schema.Properties != nil {
+		for _, name := range schema.Properties.Names() {
+			property, _ := schema.Properties.Get(name)
+			v.schema(at(path, "properties", name), property)
+		}
+	}
+	v.schema(at(path, "items"), schema.Items)
+	v.schema(at(path, "additionalProperties"), schema.AdditionalProperties)
+	for _, group := range []struct {
+		key     string
+		schemas []*Schema
+	}{{"allOf", schema.AllOf}, {"anyOf", schema.AnyOf}, {"oneOf", schema.OneOf}} {
+		for i, item := range group.schemas {
+			v.schema(at(path, group.key, fmt.Sprint(i)), item)
+		}
+	}
+}
+
+// checks that a local reference points at an existing component of the expected kind.
+// references to other documents cannot be checked here
+func (v *validator) ref(path []string, ref, kind string) {
+	if !strings.HasPrefix(ref, "#") {
+		return
+	}
+
+	prefix := "#/components/" + kind + "/"
+	name, ok := strings.CutPrefix(ref, prefix)
+	if !ok {
+		v.report(at(path, "$ref"), "reference %s must point into %s", ref, strings.TrimSuffix(prefix, "/"))
+		return
+	}
+
+	var names []string
+	if c := v.doc.Components; c != nil {
+		switch kind {
+		case "schemas":
+			names = utils.SortedKeys(c.Schemas)
+		case "parameters":
+			names = utils.SortedKeys(c.Parameters)
+		case "headers":
+			names = utils.SortedKeys(c.Headers)
+		case "responses":
+			names = utils.SortedKeys(c.Responses)
+		case "requestBodies":
+			names = utils.SortedKeys(c.RequestBodies)
+		}
+	}
+
+	for _, known := range names {
+		if known == name {
+			return
+		}
+	}
+	if suggestion := utils.ClosestMatch(name, names); suggestion != "" {
+		v.report(at(path, "$ref"), "reference %s does not resolve (did you mean %q?)", ref, suggestion)
+		return
+	}
+	v.report(at(path, "$ref"), "reference %s does not resolve", ref)
+}
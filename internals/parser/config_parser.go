@@ -1,43 +1,83 @@
 package parser
 
 import (
+	"fmt"
 	"log"
-	"os"
-
-	"gopkg.in/yaml.v2"
+	"path/filepath"
 
 	"github.com/fanchann/docunyan/internals/builder"
 	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/openapi"
 )
 
-func DocunyanConfigParser(docunyanConf string, contractFileName string) ([]byte, error) {
-	var doc models.DocunyanYAML
-	yamlFile, err := os.ReadFile(docunyanConf)
+// parses the config and the Go structs and encodes the document as JSON for opts.OpenAPI
+func DocunyanConfigParser(docunyanConf string, contractFileName string, opts Options) ([]byte, error) {
+	document, err := ParseDocument(docunyanConf, contractFileName, opts)
 	if err != nil {
-		log.Fatalf("Failed to read docunyan.yml: %v", err)
 		return nil, err
 	}
-	if err := yaml.Unmarshal(yamlFile, &doc); err != nil {
-		log.Fatalf("Failed to unmarshal yaml: %v", err)
-		return nil, err
+
+	output, err := builder.EncodeDocument(document, builder.Options{Version: opts.OpenAPI})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI spec: %w", err)
+	}
+
+	return output, nil
+}
+
+// parses the config and the Go structs into a typed OpenAPI document. config problems
+// are returned as UnknownKeys or ValidationErrors, which carry their file and line
+func ParseDocument(docunyanConf string, contractFileName string, opts Options) (*openapi.Document, error) {
+	var doc models.DocunyanYAML
+
+	// read the config and merge included files
+	source, err := LoadConfig(docunyanConf, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docunyan.yml: %w", err)
+	}
+
+	// typos such as requestbody: would otherwise be dropped silently
+	if unknown := CheckUnknownKeys(source); len(unknown) > 0 {
+		if !opts.Lenient {
+			return nil, fmt.Errorf("invalid docunyan.yml: %w", UnknownKeys(unknown))
+		}
+		for _, key := range unknown {
+			log.Printf("warning: %s", key.Error())
+		}
+	}
+
+	if err := source.Root.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
+	}
+
+	// example files are resolved relative to the config file
+	doc.BaseDir = filepath.Dir(docunyanConf)
+
+	version, err := builder.NormalizeVersion(opts.OpenAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI spec: %w", err)
 	}
 
 	schemaBuilder := NewSchemaBuilder()
+	schemaBuilder.OpenAPI31 = version == builder.OpenAPI31
 
 	// parse Go structs
 	if err := schemaBuilder.ParseGoStructs(contractFileName); err != nil {
-		log.Fatalf("Failed to parse Go structs: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to parse Go structs: %w", err)
 	}
 
 	// build schemas from structs
 	schemas := schemaBuilder.BuildSchemas()
 
-	output, err := builder.BuildOpenAPISpec(doc, schemas)
+	document, err := builder.BuildDocument(doc, schemas, builder.Options{Version: opts.OpenAPI})
 	if err != nil {
-		log.Fatalf("Failed to build OpenAPI spec: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to build OpenAPI spec: %w", err)
 	}
 
-	return output, nil
+	// dangling references and missing fields are caught before anything is written
+	if problems := ValidateDocument(document, source); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid docunyan.yml: %w", ValidationErrors(problems))
+	}
+
+	return document, nil
 }
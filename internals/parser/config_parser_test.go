@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata/golden")
+
+// the golden config fills every map of the config with several entries, so any
+// output that depends on map iteration order shows up as a difference
+func TestGeneratedSpecIsStable(t *testing.T) {
+	dir := filepath.Join("testdata", "golden")
+	config := filepath.Join(dir, "docunyan.yml")
+	goFile := filepath.Join(dir, "dto.go")
+
+	tests := []struct {
+		version string
+		golden  string
+	}{
+		{"3.0", "openapi.3.0.json"},
+		{"3.1", "openapi.3.1.json"},
+		{"2.0", "swagger.2.0.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			opts := Options{OpenAPI: tt.version}
+			first, err := DocunyanConfigParser(config, goFile, opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			second, err := DocunyanConfigParser(config, goFile, opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(first, second) {
+				t.Fatalf("two runs with the same input produced different output")
+			}
+
+			golden := filepath.Join(dir, tt.golden)
+			if *update {
+				if err := os.WriteFile(golden, first, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(first, want) {
+				t.Errorf("output differs from %s, rerun with -update if the change is intended", golden)
+			}
+		})
+	}
+}
+
+// config problems come back as errors that still carry their file and line
+func TestParseDocumentReturnsConfigLocations(t *testing.T) {
+	goFile := filepath.Join("testdata", "golden", "dto.go")
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := ParseDocument(filepath.Join("testdata", "profile", "docunyan.yml"), goFile, Options{Profile: "prod"})
+		var unknown UnknownKeys
+		if !errors.As(err, &unknown) {
+			t.Fatalf("expected unknown keys, got %v", err)
+		}
+		if want := filepath.Join("testdata", "profile", "profiles.yml"); len(unknown) != 1 || unknown[0].File != want || unknown[0].Line != 7 {
+			t.Errorf("unknown keys = %v, want %s:7", unknown, want)
+		}
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		config := filepath.Join("testdata", "validate", "dangling_ref", "docunyan.yml")
+		_, err := ParseDocument(config, goFile, Options{})
+		var problems ValidationErrors
+		if !errors.As(err, &problems) {
+			t.Fatalf("expected validation errors, got %v", err)
+		}
+		if len(problems) != 1 || problems[0].File != config || problems[0].Line != 15 {
+			t.Errorf("problems = %v, want %s:15", problems, config)
+		}
+	})
+
+	t.Run("missing config", func(t *testing.T) {
+		if _, err := ParseDocument(filepath.Join("testdata", "missing.yml"), goFile, Options{}); err == nil {
+			t.Fatalf("expected an error for a missing config")
+		}
+	})
+}
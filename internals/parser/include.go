@@ -0,0 +1,322 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+const (
+	// top-level list of files merged into the config
+	includeKey = "include"
+	// mapping key whose files are merged into the surrounding mapping
+	inlineIncludeKey = "$include"
+)
+
+// merged config tree together with every file that contributed to it
+type ConfigSource struct {
+	Root     *yaml.Node
+	Files    []string
+	Profiles []string // profiles declared in the config
+
+	origins map[*yaml.Node]string
+}
+
+type configLoader struct {
+	origins map[*yaml.Node]string // node -> file that declared it
+	stack   []string              // files currently being loaded, for cycle detection
+	files   []string
+}
+
+// reads a config file, resolves its include directives relative to the including file,
+// interpolates environment variables and applies the selected profile
+func LoadConfig(configPath string, opts Options) (*ConfigSource, error) {
+	loader := &configLoader{origins: map[*yaml.Node]string{}}
+
+	root, err := loader.load(configPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := applyProfile(root, opts.Profile, loader.origins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigSource{Root: root, Files: loader.files, Profiles: profiles, origins: loader.origins}, nil
+}
+
+// parses a single file and merges everything it includes, returning its root mapping.
+// inProfiles marks files included below the profiles section
+func (l *configLoader) load(path string, inProfiles bool) (*yaml.Node, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	for i, loading := range l.stack {
+		if loading == absPath {
+			cycle := append(append([]string{}, l.stack[i:]...), absPath)
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(l.relative(cycle), " -> "))
+		}
+	}
+	l.stack = append(l.stack, absPath)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	l.files = append(l.files, absPath)
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(document.Content) > 0 {
+		root = document.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: config must be a mapping", path)
+	}
+
+	l.track(root, absPath)
+	// profiles are interpolated once one of them is selected, the others may use
+	// variables that are not set
+	if !inProfiles {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == profilesKey {
+				continue
+			}
+			if err := interpolateEnv(root.Content[i+1], l.origins); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(l.stack) > 1 {
+		rebaseExampleFiles(root, filepath.Dir(absPath))
+	}
+
+	if err := l.resolveInline(root, absPath, nil, inProfiles); err != nil {
+		return nil, err
+	}
+
+	// top-level include list, merged into this file's root
+	if value := removeKey(root, includeKey); value != nil {
+		files, err := l.expand(value, absPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			included, err := l.load(file, inProfiles)
+			if err != nil {
+				return nil, err
+			}
+			if err := l.merge(root, included, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return root, nil
+}
+
+// replaces every $include key below node with the content of the referenced files
+func (l *configLoader) resolveInline(node *yaml.Node, file string, keys []string, inProfiles bool) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := l.resolveInline(item, file, keys, inProfiles); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		if value := removeKey(node, inlineIncludeKey); value != nil {
+			files, err := l.expand(value, file)
+			if err != nil {
+				return err
+			}
+			for _, includedFile := range files {
+				included, err := l.load(includedFile, inProfiles || (len(keys) > 0 && keys[0] == profilesKey))
+				if err != nil {
+					return err
+				}
+				if err := l.merge(node, included, keys); err != nil {
+					return err
+				}
+			}
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			childKeys := append(append([]string{}, keys...), node.Content[i].Value)
+			if err := l.resolveInline(node.Content[i+1], file, childKeys, inProfiles); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expands an include value (a path or list of paths, globs allowed) relative to the including file
+func (l *configLoader) expand(value *yaml.Node, file string) ([]string, error) {
+	patterns := []string{}
+	switch value.Kind {
+	case yaml.ScalarNode:
+		patterns = append(patterns, value.Value)
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("%s:%d: include entries must be file paths", utils.RelativePath(file), item.Line)
+			}
+			patterns = append(patterns, item.Value)
+		}
+	default:
+		return nil, fmt.Errorf("%s:%d: include must be a file path or a list of file paths", utils.RelativePath(file), value.Line)
+	}
+
+	files := []string{}
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(file), pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid include pattern %s: %w", utils.RelativePath(file), value.Line, pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s:%d: include %s matched no files", utils.RelativePath(file), value.Line, utils.RelativePath(pattern))
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// sections whose entries may come from different files, "*" matches any key.
+// anything else defined twice is a conflict
+var mergeableSections = [][]string{
+	{},
+	{"paths"},
+	{"paths", "*"},
+	{"webhooks"},
+	{"components"},
+	{"components", "*"},
+	{"securitySchemes"},
+	{"profiles"},
+}
+
+func isMergeable(keys []string) bool {
+	for _, section := range mergeableSections {
+		if len(section) != len(keys) {
+			continue
+		}
+		matched := true
+		for i, key := range section {
+			if key != "*" && key != keys[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// merges src into dst; entries of mergeable sections are combined, any other duplicate key is a conflict
+func (l *configLoader) merge(dst, src *yaml.Node, keys []string) error {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		keyPath := append(append([]string{}, keys...), key.Value)
+
+		existingKey, existing := utils.FindKey(dst, key.Value)
+		if existing == nil {
+			dst.Content = append(dst.Content, key, value)
+			continue
+		}
+
+		if existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode && isMergeable(keyPath) {
+			if err := l.merge(existing, value, keyPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return fmt.Errorf("conflicting definitions of %s in %s:%d and %s:%d",
+			strings.Join(keyPath, "."), utils.RelativePath(l.origins[existingKey]), existingKey.Line, utils.RelativePath(l.origins[key]), key.Line)
+	}
+	return nil
+}
+
+// records the file every node of the tree came from
+func (l *configLoader) track(node *yaml.Node, file string) {
+	l.origins[node] = file
+	for _, child := range node.Content {
+		l.track(child, file)
+	}
+}
+
+func (l *configLoader) relative(files []string) []string {
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		result = append(result, utils.RelativePath(file))
+	}
+	return result
+}
+
+// makes example file references absolute so they keep pointing at files next to the declaring config
+func rebaseExampleFiles(node *yaml.Node, dir string) {
+	rebase := func(value *yaml.Node) {
+		if value.Kind == yaml.ScalarNode && strings.HasSuffix(strings.ToLower(value.Value), ".json") && !filepath.IsAbs(value.Value) {
+			value.Value = filepath.Join(dir, value.Value)
+		}
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			rebaseExampleFiles(item, dir)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			switch key.Value {
+			case "example":
+				rebase(value)
+			case "examples":
+				if value.Kind == yaml.MappingNode {
+					for j := 1; j < len(value.Content); j += 2 {
+						example := value.Content[j]
+						rebase(example)
+						if _, file := utils.FindKey(example, "file"); file != nil {
+							rebase(file)
+						}
+					}
+				}
+			default:
+				rebaseExampleFiles(value, dir)
+			}
+		}
+	}
+}
+
+// removes a mapping entry and returns its value
+func removeKey(mapping *yaml.Node, key string) *yaml.Node {
+	index := utils.KeyIndex(mapping, key)
+	if index < 0 {
+		return nil
+	}
+	value := mapping.Content[index+1]
+	mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+	return value
+}
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+func TestLoadConfigIncludes(t *testing.T) {
+	dir := filepath.Join("testdata", "include")
+
+	tests := []struct {
+		name   string
+		config string
+		paths  []string // keys of the merged paths section
+		err    []string // parts of the expected error
+	}{
+		{
+			name:   "nested include",
+			config: filepath.Join(dir, "nested", "docunyan.yml"),
+			paths:  []string{"/health", "/products", "/orders"},
+		},
+		{
+			name:   "conflicting key names both files",
+			config: filepath.Join(dir, "conflict", "docunyan.yml"),
+			err: []string{
+				"conflicting definitions of info",
+				filepath.Join(dir, "conflict", "docunyan.yml") + ":1",
+				filepath.Join(dir, "conflict", "other.yml") + ":1",
+			},
+		},
+		{
+			name:   "cycle",
+			config: filepath.Join(dir, "cycle", "a.yml"),
+			err: []string{
+				"include cycle: " + strings.Join([]string{
+					filepath.Join(dir, "cycle", "a.yml"),
+					filepath.Join(dir, "cycle", "b.yml"),
+					filepath.Join(dir, "cycle", "a.yml"),
+				}, " -> "),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := LoadConfig(tt.config, Options{})
+			if len(tt.err) > 0 {
+				if err == nil {
+					t.Fatalf("expected an error containing %q", tt.err)
+				}
+				for _, part := range tt.err {
+					if !strings.Contains(err.Error(), part) {
+						t.Errorf("error %q does not contain %q", err, part)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, paths := utils.FindKey(source.Root, "paths")
+			if paths == nil {
+				t.Fatalf("merged config has no paths")
+			}
+			got := []string{}
+			for i := 0; i+1 < len(paths.Content); i += 2 {
+				got = append(got, paths.Content[i].Value)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.paths, ",") {
+				t.Errorf("paths = %v, want %v", got, tt.paths)
+			}
+		})
+	}
+}
+
+func TestLoadConfigRebasesExampleFiles(t *testing.T) {
+	source, err := LoadConfig(filepath.Join("testdata", "include", "rebase", "docunyan.yml"), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	included, err := filepath.Abs(filepath.Join("testdata", "include", "rebase", "paths"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := nodeAt(t, source.Root, "paths", "/orders/:id", "get", "responses", "200")
+
+	tests := []struct {
+		keys []string
+		want string
+	}{
+		{[]string{"example"}, filepath.Join(included, "examples", "order.json")},
+		{[]string{"examples", "paid"}, filepath.Join(included, "examples", "paid.json")},
+		{[]string{"examples", "refunded", "file"}, filepath.Join(included, "examples", "refunded.json")},
+		{[]string{"examples", "refunded", "summary"}, "refunded order"},
+		{[]string{"examples", "inline", "value", "id"}, "o1"},
+	}
+	for _, tt := range tests {
+		if got := nodeAt(t, response, tt.keys...).Value; got != tt.want {
+			t.Errorf("%s = %q, want %q", strings.Join(tt.keys, "."), got, tt.want)
+		}
+	}
+}
+
+// follows mapping keys below node
+func nodeAt(t *testing.T, node *yaml.Node, keys ...string) *yaml.Node {
+	t.Helper()
+	for _, key := range keys {
+		_, value := utils.FindKey(node, key)
+		if value == nil {
+			t.Fatalf("key %s not found", key)
+		}
+		node = value
+	}
+	return node
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fanchann/docunyan/internals/openapi"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// problem of the generated document together with the config location that caused it
+type ValidationError struct {
+	File string // empty when the value comes from the Go structs
+	Line int
+	openapi.Problem
+}
+
+func (v ValidationError) Error() string {
+	if v.File == "" {
+		return v.Problem.Error()
+	}
+	return fmt.Sprintf("%s:%d: %s", v.File, v.Line, v.Problem.Error())
+}
+
+// every problem of a generated document, as returned by ParseDocument
+type ValidationErrors []ValidationError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, problem := range v {
+		messages = append(messages, problem.Error())
+	}
+	return "invalid OpenAPI spec:\n  " + strings.Join(messages, "\n  ")
+}
+
+// validates the generated document and points every problem back at the config
+func ValidateDocument(document *openapi.Document, source *ConfigSource) []ValidationError {
+	errors := []ValidationError{}
+	for _, problem := range openapi.Validate(document) {
+		file, line := source.Locate(problem.Path)
+		errors = append(errors, ValidationError{File: file, Line: line, Problem: problem})
+	}
+	return errors
+}
+
+// finds the config key a location of the generated document came from. the walk stops
+// at the deepest key that exists, so values the builder adds point at their closest
+// config ancestor. returns an empty file for locations outside the config
+func (s *ConfigSource) Locate(path []string) (string, int) {
+	if len(path) == 0 || s.Root == nil {
+		return "", 0
+	}
+
+	switch {
+	case path[0] == "x-webhooks":
+		path = append([]string{"webhooks"}, path[1:]...)
+	case len(path) > 1 && path[0] == "components" && path[1] == "schemas":
+		// built from the Go structs
+		return "", 0
+	}
+
+	node, key := s.Root, (*yaml.Node)(nil)
+	for i := 0; i < len(path); i++ {
+		segment := path[i]
+
+		// media types are implied by the config, responses.200.schema becomes
+		// responses.200.content.application/json.schema
+		if segment == "content" && i+1 < len(path) && strings.Contains(path[i+1], "/") {
+			i++
+			continue
+		}
+
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			var found *yaml.Node
+			found, next = locateKey(node, segment, i == 1 && path[0] == "paths")
+			if found != nil {
+				key = found
+			}
+		case yaml.SequenceNode:
+			// generated parameters shift the positions of the declared ones
+			index, err := strconv.Atoi(segment)
+			if err == nil && path[i-1] != "parameters" && index < len(node.Content) {
+				next = node.Content[index]
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+
+	if key == nil {
+		key = s.Root
+	}
+	return utils.RelativePath(s.origins[key]), key.Line
+}
+
+// finds a mapping entry, paths match in their OpenAPI form and methods in any case
+func locateKey(mapping *yaml.Node, segment string, isPath bool) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i]
+		switch {
+		case key.Value == segment,
+			isPath && utils.NormalizePathParams(key.Value) == segment,
+			strings.EqualFold(key.Value, segment) && utils.Contains(utils.HTTPMethods, strings.ToLower(segment)):
+			return key, mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fanchann/docunyan/internals/builder"
+	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/openapi"
+)
+
+func TestValidateDocumentLocatesProblems(t *testing.T) {
+	dir := filepath.Join("testdata", "validate")
+
+	tests := []struct {
+		name   string
+		config string
+		// changes the built document, for problems the builder already rejects in the config
+		change  func(document *openapi.Document)
+		path    string // location in the generated document
+		message string // part of the expected message
+		file    string
+		line    int
+	}{
+		{
+			name:    "dangling ref",
+			config:  filepath.Join(dir, "dangling_ref", "docunyan.yml"),
+			path:    "paths./orders/{id}.get.responses.404.$ref",
+			message: `reference #/components/responses/NotFund does not resolve (did you mean "NotFound"?)`,
+			file:    filepath.Join(dir, "dangling_ref", "docunyan.yml"),
+			line:    15,
+		},
+		{
+			name:   "duplicate operationId",
+			config: filepath.Join(dir, "duplicate_operation_id", "docunyan.yml"),
+			change: func(document *openapi.Document) {
+				document.Paths["/orders/{id}"].Get.OperationID = "listOrders"
+			},
+			path:    "paths./orders/{id}.get.operationId",
+			message: `operationId "listOrders" is already used by paths./orders.get`,
+			file:    filepath.Join(dir, "duplicate_operation_id", "docunyan.yml"),
+			line:    13,
+		},
+		{
+			name:    "path parameter mismatch",
+			config:  filepath.Join(dir, "path_parameter", "docunyan.yml"),
+			path:    "paths./orders/{id}.get.parameters",
+			message: `path parameter "orderId" does not appear in /orders/{id}`,
+			file:    filepath.Join(dir, "path_parameter", "paths", "orders.yml"),
+			line:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := LoadConfig(tt.config, Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var doc models.DocunyanYAML
+			if err := source.Root.Decode(&doc); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			document, err := builder.BuildDocument(doc, map[string]*openapi.Schema{}, builder.Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.change != nil {
+				tt.change(document)
+			}
+
+			problems := ValidateDocument(document, source)
+			if len(problems) != 1 {
+				t.Fatalf("expected one problem, got %v", problems)
+			}
+			problem := problems[0]
+			if path := strings.Join(problem.Path, "."); path != tt.path {
+				t.Errorf("path = %s, want %s", path, tt.path)
+			}
+			if !strings.Contains(problem.Message, tt.message) {
+				t.Errorf("message %q does not contain %q", problem.Message, tt.message)
+			}
+			if problem.File != tt.file || problem.Line != tt.line {
+				t.Errorf("location = %s:%d, want %s:%d", problem.File, problem.Line, tt.file, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+const profilesKey = "profiles"
+
+// ${VAR} or ${VAR:-default}; $${...} escapes a literal ${...}
+var envPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// options that change how the config is loaded
+type Options struct {
+	Profile string // entry of profiles: applied on top of the base config
+	Lenient bool   // report unknown keys as warnings instead of errors
+	OpenAPI string // OpenAPI version of the generated document, 3.0, 3.1 or 2.0
+}
+
+// a ${VAR} reference without default to a variable that is not set
+type MissingEnvError struct {
+	File  string
+	Line  int
+	Names []string
+}
+
+func (e *MissingEnvError) Error() string {
+	return fmt.Sprintf("%s:%d: environment variable %s is not set and has no default", e.File, e.Line, strings.Join(e.Names, ", "))
+}
+
+// replaces ${VAR:-default} references in every scalar value of the tree, origins
+// name the file of each node for errors
+func interpolateEnv(node *yaml.Node, origins map[*yaml.Node]string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		// keys are left untouched, only values are interpolated
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolateEnv(node.Content[i], origins); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := interpolateEnv(child, origins); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "${") {
+			return nil
+		}
+
+		var missing []string
+		value := envPattern.ReplaceAllStringFunc(node.Value, func(match string) string {
+			if strings.HasPrefix(match, "$$") {
+				return match[1:]
+			}
+
+			parts := envPattern.FindStringSubmatch(match)
+			if env, ok := os.LookupEnv(parts[1]); ok {
+				return env
+			}
+			if parts[2] != "" {
+				return parts[3]
+			}
+			missing = append(missing, parts[1])
+			return match
+		})
+
+		if len(missing) > 0 {
+			return &MissingEnvError{File: utils.RelativePath(origins[node]), Line: node.Line, Names: missing}
+		}
+
+		if value != node.Value {
+			node.Value = value
+			// let plain scalars resolve to numbers or booleans after substitution
+			if node.Style == 0 {
+				node.Tag = ""
+			}
+		}
+	}
+	return nil
+}
+
+// removes the profiles section and applies the selected profile on top of the config,
+// returning the names of every declared profile. only the selected profile is interpolated
+func applyProfile(root *yaml.Node, profile string, origins map[*yaml.Node]string) ([]string, error) {
+	profiles := removeKey(root, profilesKey)
+
+	available := []string{}
+	if profiles != nil {
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			available = append(available, profiles.Content[i].Value)
+		}
+	}
+
+	if profile == "" {
+		return available, nil
+	}
+
+	if profiles == nil {
+		return nil, fmt.Errorf("profile %q selected but the config has no profiles section", profile)
+	}
+
+	_, selected := utils.FindKey(profiles, profile)
+	if selected == nil {
+		sorted := append([]string{}, available...)
+		sort.Strings(sorted)
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(sorted, ", "))
+	}
+
+	if selected.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("profile %q must be a mapping", profile)
+	}
+
+	if err := interpolateEnv(selected, origins); err != nil {
+		return nil, err
+	}
+	override(root, selected)
+	return available, nil
+}
+
+// deep merges src over dst: mappings are merged, anything else is replaced. replaced
+// entries take the profile's key and value nodes, so they keep pointing at the file
+// and line of the profile
+func override(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		index := utils.KeyIndex(dst, key.Value)
+		switch {
+		case index < 0:
+			dst.Content = append(dst.Content, key, value)
+		case dst.Content[index+1].Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			override(dst.Content[index+1], value)
+		default:
+			dst.Content[index], dst.Content[index+1] = key, value
+		}
+	}
+}
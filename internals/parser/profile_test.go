@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileOverrideKeepsOrigins(t *testing.T) {
+	dir := filepath.Join("testdata", "profile")
+	base := filepath.Join(dir, "docunyan.yml")
+	profiles := filepath.Join(dir, "profiles.yml")
+
+	source, err := LoadConfig(base, Options{Profile: "prod"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		path []string
+		file string
+		line int
+	}{
+		{[]string{"info", "title"}, profiles, 4},
+		{[]string{"info", "version"}, base, 3},
+		{[]string{"servers", "0", "url"}, profiles, 6},
+	}
+	for _, tt := range tests {
+		file, line := source.Locate(tt.path)
+		if file != tt.file || line != tt.line {
+			t.Errorf("Locate(%v) = %s:%d, want %s:%d", tt.path, file, line, tt.file, tt.line)
+		}
+	}
+
+	unknown := CheckUnknownKeys(source)
+	if len(unknown) != 1 {
+		t.Fatalf("unknown keys = %v, want one", unknown)
+	}
+	if unknown[0].File != profiles || unknown[0].Line != 7 || unknown[0].Key != "descripton" {
+		t.Errorf("unknown key = %v, want %s:7 descripton", unknown[0], profiles)
+	}
+}
+
+func TestLoadConfigInterpolatesSelectedProfileOnly(t *testing.T) {
+	config := filepath.Join("testdata", "profile", "env", "docunyan.yml")
+
+	tests := []struct {
+		name    string
+		profile string
+		env     string // value of DOCUNYAN_TEST_PROD_URL, unset when empty
+		url     string
+		missing bool
+	}{
+		{name: "no profile", url: "http://localhost:8080"},
+		{name: "other profile", profile: "dev", url: "http://dev.example.com"},
+		{name: "selected profile", profile: "prod", env: "https://api.example.com", url: "https://api.example.com"},
+		{name: "selected profile without variable", profile: "prod", missing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("DOCUNYAN_TEST_PROD_URL", tt.env)
+			}
+
+			source, err := LoadConfig(config, Options{Profile: tt.profile})
+			if tt.missing {
+				var missing *MissingEnvError
+				if !errors.As(err, &missing) {
+					t.Fatalf("expected a missing variable error, got %v", err)
+				}
+				if missing.File != config || missing.Line != 12 {
+					t.Errorf("location = %s:%d, want %s:12", missing.File, missing.Line, config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			servers := nodeAt(t, source.Root, "servers")
+			if url := nodeAt(t, servers.Content[0], "url").Value; url != tt.url {
+				t.Errorf("server url = %q, want %q", url, tt.url)
+			}
+		})
+	}
+}
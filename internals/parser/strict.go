@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+var (
+	pathItemType            = reflect.TypeOf(models.PathItem{})
+	securityRequirementType = reflect.TypeOf(models.SecurityRequirement{})
+	schemeRequirementType   = reflect.TypeOf(models.SchemeRequirement{})
+)
+
+// key in the config that does not match any field of the models
+type UnknownKey struct {
+	File       string
+	Line       int
+	Path       string // location of the mapping that contains the key, e.g. paths./products.get
+	Key        string
+	Suggestion string
+}
+
+func (u UnknownKey) Error() string {
+	msg := fmt.Sprintf("%s:%d: unknown key %q", u.File, u.Line, u.Key)
+	if u.Path != "" {
+		msg += " in " + u.Path
+	}
+	if u.Suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", u.Suggestion)
+	}
+	return msg
+}
+
+// unknown keys of a config, as returned by ParseDocument outside lenient mode
+type UnknownKeys []UnknownKey
+
+func (u UnknownKeys) Error() string {
+	messages := make([]string, 0, len(u))
+	for _, key := range u {
+		messages = append(messages, key.Error())
+	}
+	return "unknown keys in config:\n  " + strings.Join(messages, "\n  ")
+}
+
+// reports every key of the config that would be silently ignored when decoding
+func CheckUnknownKeys(source *ConfigSource) []UnknownKey {
+	checker := &keyChecker{origins: source.origins}
+	checker.check(source.Root, reflect.TypeOf(models.DocunyanYAML{}), "")
+
+	sort.SliceStable(checker.unknown, func(i, j int) bool {
+		if checker.unknown[i].File != checker.unknown[j].File {
+			return checker.unknown[i].File < checker.unknown[j].File
+		}
+		return checker.unknown[i].Line < checker.unknown[j].Line
+	})
+	return checker.unknown
+}
+
+type keyChecker struct {
+	origins map[*yaml.Node]string
+	unknown []UnknownKey
+}
+
+func (k *keyChecker) check(node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == securityRequirementType || t == schemeRequirementType:
+		// names and scope lists, shape errors are reported by the decoder
+		return
+	case t == pathItemType:
+		k.checkPathItem(node, path)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		// scalar shorthands (e.g. requestBody: Schema) are handled by the model
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+
+		fields, known := yamlFields(t)
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				k.report(key, path, known)
+				continue
+			}
+			k.check(value, field, join(path, key.Value))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k.check(node.Content[i+1], t.Elem(), join(path, node.Content[i].Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			k.check(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// path entries are HTTP methods plus the shared parameters list
+func (k *keyChecker) checkPathItem(node *yaml.Node, path string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	known := append([]string{"parameters"}, utils.HTTPMethods...)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch {
+		case key.Value == "parameters":
+			k.check(value, reflect.TypeOf([]models.Parameter{}), join(path, key.Value))
+		case utils.IsHTTPMethod(key.Value):
+			k.check(value, reflect.TypeOf(models.EndpointDetail{}), join(path, key.Value))
+		default:
+			k.report(key, path, known)
+		}
+	}
+}
+
+func (k *keyChecker) report(key *yaml.Node, path string, known []string) {
+	k.unknown = append(k.unknown, UnknownKey{
+		File:       utils.RelativePath(k.origins[key]),
+		Line:       key.Line,
+		Path:       path,
+		Key:        key.Value,
+		Suggestion: utils.ClosestMatch(key.Value, known),
+	})
+}
+
+// maps yaml keys of a struct to their field types, names lists the keys in field order
+// so suggestions between equally close keys do not depend on map iteration
+func yamlFields(t reflect.Type) (fields map[string]reflect.Type, names []string) {
+	fields = map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+		names = append(names, name)
+	}
+	return fields, names
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fanchann/docunyan/internals/builder"
+	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/openapi"
+)
+
+func TestCheckUnknownKeysSuggestionIsStable(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "docunyan.yml")
+	content := `info:
+  title: Shop API
+  version: 1.0.0
+paths:
+  /products:
+    get:
+      parameterz: id
+      responses:
+        200:
+          description: ok
+`
+	if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// parameterz is one edit away from both parameter and parameters, the field
+	// declared first wins
+	for i := 0; i < 20; i++ {
+		source, err := LoadConfig(config, Options{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		unknown := CheckUnknownKeys(source)
+		if len(unknown) != 1 {
+			t.Fatalf("unknown keys = %v, want one", unknown)
+		}
+		if unknown[0].Line != 7 || unknown[0].Suggestion != "parameter" {
+			t.Fatalf("unknown key = %v, want line 7 with suggestion parameter", unknown[0])
+		}
+	}
+}
+
+func TestLenientConfigSkipsUnknownPathKeys(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "docunyan.yml")
+	content := `info:
+  title: Shop API
+  version: 1.0.0
+paths:
+  /products:
+    gett:
+      responses:
+        200:
+          description: ok
+    post:
+      responses:
+        201:
+          description: created
+`
+	if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := LoadConfig(config, Options{Lenient: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if unknown := CheckUnknownKeys(source); len(unknown) != 1 || unknown[0].Key != "gett" {
+		t.Fatalf("unknown keys = %v, want gett", unknown)
+	}
+
+	// the typo is reported above, building goes on without it
+	var doc models.DocunyanYAML
+	if err := source.Root.Decode(&doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	document, err := builder.BuildDocument(doc, map[string]*openapi.Schema{}, builder.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if methods, _ := document.Paths["/products"].Operations(); len(methods) != 1 || methods[0] != "post" {
+		t.Errorf("operations = %v, want [post]", methods)
+	}
+}
@@ -8,20 +8,25 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/fanchann/docunyan/internals/openapi"
 	"github.com/fanchann/docunyan/internals/utils"
 )
 
 type SchemaBuilder struct {
 	Structs       map[string]*ast.StructType
 	StructDocs    map[string]string
-	StructSchemas map[string]map[string]interface{}
+	StructSchemas map[string]*openapi.Schema
+
+	// JSON Schema 2020-12 schemas for OpenAPI 3.1: pointer fields are nullable and
+	// references carry their siblings directly
+	OpenAPI31 bool
 }
 
 func NewSchemaBuilder() *SchemaBuilder {
 	return &SchemaBuilder{
 		Structs:       make(map[string]*ast.StructType),
 		StructDocs:    make(map[string]string),
-		StructSchemas: make(map[string]map[string]interface{}),
+		StructSchemas: make(map[string]*openapi.Schema),
 	}
 }
 
@@ -60,9 +65,9 @@ func (s *SchemaBuilder) ParseGoStructs(contractFileName string) error {
 	return nil
 }
 
-func (s *SchemaBuilder) BuildSchemas() map[string]interface{} {
+func (s *SchemaBuilder) BuildSchemas() map[string]*openapi.Schema {
 	processed := map[string]bool{}
-	schemas := map[string]interface{}{}
+	schemas := map[string]*openapi.Schema{}
 
 	var processStruct func(name string)
 	processStruct = func(name string) {
@@ -87,22 +92,24 @@ func (s *SchemaBuilder) BuildSchemas() map[string]interface{} {
 		schema := s.StructSchemas[name]
 
 		if desc, ok := s.StructDocs[name]; ok && desc != "" {
-			schema["description"] = strings.TrimSpace(desc)
+			schema.Description = strings.TrimSpace(desc)
 		}
 
 		schemas[name] = schema
 		processed[name] = true
 	}
 
-	for name := range s.Structs {
+	// sorted so that processing (and any logging) does not depend on map order
+	for _, name := range utils.SortedKeys(s.Structs) {
 		processStruct(name)
 	}
 
 	return schemas
 }
 
-func (s *SchemaBuilder) parseStructSpec(structName string, st *ast.StructType) map[string]interface{} {
-	properties := map[string]interface{}{}
+func (s *SchemaBuilder) parseStructSpec(structName string, st *ast.StructType) *openapi.Schema {
+	// properties follow the field order of the struct
+	properties := openapi.NewProperties()
 	required := []string{}
 
 	for _, field := range st.Fields.List {
@@ -110,15 +117,18 @@ func (s *SchemaBuilder) parseStructSpec(structName string, st *ast.StructType) m
 		if len(field.Names) > 0 {
 			fieldName = field.Names[0].Name
 		} else {
-			// Embedded struct
-			switch t := field.Type.(type) {
-			case *ast.Ident:
-				embeddedType := t.Name
-				if embeddedSchema, ok := s.StructSchemas[embeddedType]; ok {
-					if embProps, ok := embeddedSchema["properties"].(map[string]interface{}); ok {
-						for k, v := range embProps {
-							properties[k] = v
-						}
+			// Embedded struct, its fields are spliced in at this position
+			embeddedType := utils.ExprToTypeString(field.Type)
+			if embeddedSchema, ok := s.StructSchemas[embeddedType]; ok {
+				if embeddedSchema.Properties != nil {
+					for _, k := range embeddedSchema.Properties.Names() {
+						v, _ := embeddedSchema.Properties.Get(k)
+						properties.Set(k, v)
+					}
+				}
+				for _, k := range embeddedSchema.Required {
+					if !utils.Contains(required, k) {
+						required = append(required, k)
 					}
 				}
 			}
@@ -127,6 +137,7 @@ func (s *SchemaBuilder) parseStructSpec(structName string, st *ast.StructType) m
 
 		jsonTag := fieldName
 		isRequired := true
+		var example, enum string
 
 		if tag := field.Tag; tag != nil {
 			tagValue := reflect.StructTag(strings.Trim(tag.Value, "`"))
@@ -148,45 +159,74 @@ func (s *SchemaBuilder) parseStructSpec(structName string, st *ast.StructType) m
 					isRequired = true
 				}
 			}
+			example = tagValue.Get("example")
+			enum = tagValue.Get("enum")
 		}
 
-		if isRequired {
+		if isRequired && !utils.Contains(required, jsonTag) {
 			required = append(required, jsonTag)
 		}
 
+		// pointer fields may be null
+		_, pointer := field.Type.(*ast.StarExpr)
+		nullable := pointer && s.OpenAPI31
+
+		var propSchema *openapi.Schema
 		typeStr := utils.ExprToTypeString(field.Type)
 		if strings.HasPrefix(typeStr, "[]") {
 			elemType := strings.TrimPrefix(typeStr, "[]")
+			items := &openapi.Schema{Type: openapi.SchemaType{utils.GoTypeToSwaggerType(elemType)}}
 			if _, ok := s.StructSchemas[elemType]; ok {
-				properties[jsonTag] = map[string]interface{}{
-					"type":  "array",
-					"items": map[string]interface{}{"$ref": "#/components/schemas/" + elemType},
-				}
-			} else {
-				properties[jsonTag] = map[string]interface{}{
-					"type":  "array",
-					"items": map[string]interface{}{"type": utils.GoTypeToSwaggerType(elemType)},
-				}
+				items = &openapi.Schema{Ref: "#/components/schemas/" + elemType}
 			}
+			propSchema = &openapi.Schema{Type: openapi.SchemaType{"array"}, Items: items}
 		} else if _, ok := s.StructSchemas[typeStr]; ok {
-			properties[jsonTag] = map[string]interface{}{
-				"$ref": "#/components/schemas/" + typeStr,
+			propSchema = &openapi.Schema{Ref: "#/components/schemas/" + typeStr}
+			if nullable {
+				// a plain reference next to the null type, no wrapper the schema would
+				// need when moved into $defs
+				propSchema = &openapi.Schema{AnyOf: []*openapi.Schema{propSchema, {Type: openapi.SchemaType{"null"}}}}
+				nullable = false
 			}
 		} else {
-			propSchema := map[string]interface{}{"type": utils.GoTypeToSwaggerType(typeStr)}
+			propSchema = &openapi.Schema{Type: openapi.SchemaType{utils.GoTypeToSwaggerType(typeStr)}}
 			if typeStr == "time.Time" {
-				propSchema["format"] = "date-time"
+				propSchema.Format = "date-time"
+			}
+		}
+
+		propSchema.Nullable = nullable
+
+		// values from `example:"..."` and `enum:"a,b"` tags
+		if example != "" {
+			propSchema.Example = tagExampleValue(example, propSchema.Type)
+		}
+		if enum != "" {
+			for _, value := range strings.Split(enum, ",") {
+				propSchema.Enum = append(propSchema.Enum, tagExampleValue(strings.TrimSpace(value), propSchema.Type))
 			}
-			properties[jsonTag] = propSchema
 		}
+
+		properties.Set(jsonTag, propSchema)
 	}
 
-	schema := map[string]interface{}{
-		"type":       "object",
-		"properties": properties,
+	schema := &openapi.Schema{
+		Type:       openapi.SchemaType{"object"},
+		Properties: properties,
 	}
 	if len(required) > 0 {
-		schema["required"] = required
+		schema.Required = required
 	}
 	return schema
 }
+
+// converts a struct tag value to the type of the property it describes
+func tagExampleValue(value string, schemaType openapi.SchemaType) interface{} {
+	if len(schemaType) == 1 {
+		switch schemaType[0] {
+		case "integer", "number", "boolean":
+			return utils.InferType(value)
+		}
+	}
+	return value
+}
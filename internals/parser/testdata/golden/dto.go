@@ -0,0 +1,30 @@
+package dto
+
+// Product item
+type ProductResponse struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Price    float64  `json:"price,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Category string   `json:"category,omitempty"`
+}
+
+type CreateProductRequest struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+// pointers and example/enum tags only change the 3.1 output
+type OrderResponse struct {
+	ID        string            `json:"id" example:"ord_1"`
+	Status    string            `json:"status" enum:"pending,paid"`
+	Items     []ProductResponse `json:"items"`
+	Total     *float64          `json:"total" example:"9.5"`
+	Coupon    *ProductResponse  `json:"coupon,omitempty"`
+	CreatedAt string            `json:"created_at"`
+}
+
+type ErrorResponse struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
@@ -0,0 +1,378 @@
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fanchann/docunyan/internals/openapi"
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+// converts a document into a collection with one folder per tag, requests without
+// tags stay at the top level. the second result lists what could not be converted
+func Build(doc *openapi.Document) (*Collection, []string) {
+	c := &converter{doc: doc, warnings: map[string]bool{}}
+
+	collection := &Collection{
+		Info: Info{
+			Name:        doc.Info.Title,
+			Description: doc.Info.Description,
+			Schema:      schemaURL,
+		},
+		Item: []Item{},
+	}
+
+	collection.Variable = c.baseURLVariables()
+
+	// global security applies to every request that does not set its own
+	if len(doc.Security) > 0 {
+		collection.Auth = c.auth(doc.Security, "global security")
+	}
+
+	folders := map[string]*Item{}
+	for _, path := range utils.SortedKeys(doc.Paths) {
+		methods, operations := doc.Paths[path].Operations()
+		for i, method := range methods {
+			operation := operations[i]
+			item := c.request(path, method, doc.Paths[path], operation)
+
+			if len(operation.Tags) == 0 {
+				collection.Item = append(collection.Item, item)
+				continue
+			}
+			folder, ok := folders[operation.Tags[0]]
+			if !ok {
+				folder = &Item{Name: operation.Tags[0], Item: []Item{}}
+				folders[operation.Tags[0]] = folder
+			}
+			folder.Item = append(folder.Item, item)
+		}
+	}
+
+	// folders follow the declared tags, tags used without a declaration come last
+	folderItems := []Item{}
+	for _, tag := range doc.Tags {
+		if folder, ok := folders[tag.Name]; ok {
+			folder.Description = tag.Description
+			folderItems = append(folderItems, *folder)
+			delete(folders, tag.Name)
+		}
+	}
+	for _, name := range utils.SortedKeys(folders) {
+		folderItems = append(folderItems, *folders[name])
+	}
+	collection.Item = append(folderItems, collection.Item...)
+
+	collection.Variable = append(collection.Variable, c.authVariables()...)
+	return collection, c.sortedWarnings()
+}
+
+type converter struct {
+	doc          *openapi.Document
+	warnings     map[string]bool
+	authVarNames []string // placeholders used by auth settings, in order of first use
+}
+
+func (c *converter) warn(format string, args ...interface{}) {
+	c.warnings["postman: "+fmt.Sprintf(format, args...)] = true
+}
+
+func (c *converter) sortedWarnings() []string {
+	warnings := make([]string, 0, len(c.warnings))
+	for warning := range c.warnings {
+		warnings = append(warnings, warning)
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// baseUrl from the first server, its {variables} become collection variables in URL order
+func (c *converter) baseURLVariables() []KeyValue {
+	if len(c.doc.Servers) == 0 {
+		return []KeyValue{{Key: "baseUrl", Value: "http://localhost", Type: "string"}}
+	}
+
+	server := c.doc.Servers[0]
+	baseURL := strings.TrimSuffix(server.URL, "/")
+	variables := []KeyValue{}
+	for _, name := range utils.ExtractTemplateVars(server.URL) {
+		if strings.Contains(baseURL, "{{"+name+"}}") {
+			continue
+		}
+		baseURL = strings.ReplaceAll(baseURL, "{"+name+"}", "{{"+name+"}}")
+		// undeclared variables are left empty for the user to fill in
+		variable := server.Variables[name]
+		variables = append(variables, KeyValue{Key: name, Value: variable.Default, Type: "string", Description: variable.Description})
+	}
+
+	description := server.Description
+	if len(c.doc.Servers) > 1 {
+		others := []string{}
+		for _, other := range c.doc.Servers[1:] {
+			others = append(others, other.URL)
+		}
+		description = strings.TrimSpace(description + " (other servers: " + strings.Join(others, ", ") + ")")
+	}
+
+	return append([]KeyValue{{Key: "baseUrl", Value: baseURL, Type: "string", Description: description}}, variables...)
+}
+
+func (c *converter) request(path, method string, item *openapi.PathItem, operation *openapi.Operation) Item {
+	name := operation.Summary
+	if name == "" {
+		name = operation.OperationID
+	}
+	if name == "" {
+		name = strings.ToUpper(method) + " " + path
+	}
+
+	request := &Request{
+		Method:      strings.ToUpper(method),
+		Header:      []KeyValue{},
+		Description: operation.Description,
+	}
+
+	// path segments use :name variables
+	segments := []string{}
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = ":" + strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		}
+		segments = append(segments, segment)
+	}
+	request.URL = URL{Host: []string{"{{baseUrl}}"}, Path: segments}
+
+	for _, param := range append(append([]*openapi.Parameter{}, item.Parameters...), operation.Parameters...) {
+		param = c.resolveParameter(param)
+		if param == nil {
+			continue
+		}
+		value := c.paramValue(param)
+		switch param.In {
+		case "path":
+			request.URL.Variable = append(request.URL.Variable, KeyValue{Key: param.Name, Value: value, Description: param.Description})
+		case "query":
+			request.URL.Query = append(request.URL.Query, KeyValue{Key: param.Name, Value: value, Description: param.Description, Disabled: !param.Required})
+		case "header":
+			request.Header = append(request.Header, KeyValue{Key: param.Name, Value: value, Description: param.Description, Disabled: !param.Required})
+		case "cookie":
+			c.warn("cookie parameter %s of %s %s is not supported and was dropped", param.Name, strings.ToUpper(method), path)
+		}
+	}
+
+	if body := c.resolveRequestBody(operation.RequestBody); body != nil && len(body.Content) > 0 {
+		mime := utils.SortedKeys(body.Content)[0]
+		if _, ok := body.Content["application/json"]; ok {
+			mime = "application/json"
+		}
+		request.Header = append(request.Header, KeyValue{Key: "Content-Type", Value: mime})
+		request.Body = c.body(body.Content[mime])
+	}
+	request.Header = append(request.Header, KeyValue{Key: "Accept", Value: "application/json"})
+
+	if operation.Security != nil {
+		owner := strings.ToUpper(method) + " " + path
+		request.Auth = c.auth(*operation.Security, owner)
+	}
+
+	request.URL.Raw = rawURL(request.URL)
+	return Item{Name: name, Request: request}
+}
+
+// builds the raw form of a URL, e.g. {{baseUrl}}/products/:id?limit=10
+func rawURL(u URL) string {
+	raw := strings.Join(u.Host, "")
+	if len(u.Path) > 0 {
+		raw += "/" + strings.Join(u.Path, "/")
+	}
+	query := []string{}
+	for _, param := range u.Query {
+		if !param.Disabled {
+			query = append(query, param.Key+"="+param.Value)
+		}
+	}
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+	return raw
+}
+
+// example value of a parameter as text
+func (c *converter) paramValue(param *openapi.Parameter) string {
+	if param.Schema == nil {
+		return ""
+	}
+	// placeholders of plain strings are not useful as values
+	if param.Schema.Example == nil && len(param.Schema.Examples) == 0 && len(param.Schema.Enum) == 0 &&
+		param.Schema.Default == nil && len(param.Schema.Type) > 0 && param.Schema.Type[0] == "string" {
+		return ""
+	}
+	switch value := c.doc.SampleValue(param.Schema).(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	}
+}
+
+// raw JSON body from the media type example or the schema
+func (c *converter) body(media *openapi.MediaType) *Body {
+	value := media.Example
+	if value == nil {
+		for _, name := range utils.SortedKeys(media.Examples) {
+			if media.Examples[name].Value != nil {
+				value = media.Examples[name].Value
+				break
+			}
+		}
+	}
+	if value == nil {
+		value = c.doc.SampleValue(media.Schema)
+	}
+
+	raw, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		raw = []byte("{}")
+	}
+
+	body := &Body{Mode: "raw", Raw: string(raw), Options: &BodyOptions{}}
+	body.Options.Raw.Language = "json"
+	return body
+}
+
+// Postman supports a single scheme per request, the first one of the first requirement is used
+func (c *converter) auth(requirements []openapi.SecurityRequirement, owner string) *Auth {
+	if len(requirements) == 0 || len(requirements[0]) == 0 {
+		return &Auth{Type: "noauth"}
+	}
+
+	names := utils.SortedKeys(requirements[0])
+	if len(requirements) > 1 || len(names) > 1 {
+		c.warn("%s lists several security schemes, only %s is configured", owner, names[0])
+	}
+
+	name := names[0]
+	var scheme *openapi.SecurityScheme
+	if c.doc.Components != nil {
+		scheme = c.doc.Components.SecuritySchemes[name]
+	}
+	if scheme == nil {
+		c.warn("%s uses unknown security scheme %s", owner, name)
+		return nil
+	}
+
+	switch scheme.Type {
+	case "http":
+		switch strings.ToLower(scheme.Scheme) {
+		case "bearer":
+			return &Auth{Type: "bearer", Attributes: []KeyValue{
+				{Key: "token", Value: c.authVariable(name), Type: "string"},
+			}}
+		case "basic":
+			return &Auth{Type: "basic", Attributes: []KeyValue{
+				{Key: "username", Value: c.authVariable(name + "Username"), Type: "string"},
+				{Key: "password", Value: c.authVariable(name + "Password"), Type: "string"},
+			}}
+		}
+	case "apiKey":
+		if scheme.In == "cookie" {
+			break
+		}
+		return &Auth{Type: "apikey", Attributes: []KeyValue{
+			{Key: "key", Value: scheme.Name, Type: "string"},
+			{Key: "value", Value: c.authVariable(name), Type: "string"},
+			{Key: "in", Value: scheme.In, Type: "string"},
+		}}
+	case "oauth2":
+		if auth := c.oauth2(scheme.Flows, requirements[0][name], name); auth != nil {
+			return auth
+		}
+	}
+
+	c.warn("security scheme %s (%s) is not supported, configure its auth by hand", name, scheme.Type)
+	return nil
+}
+
+// oauth2 settings of the first flow, in the order Postman lists its grant types
+func (c *converter) oauth2(flows *openapi.OAuthFlows, scopes []string, name string) *Auth {
+	if flows == nil {
+		return nil
+	}
+
+	for _, candidate := range []struct {
+		grantType string
+		flow      *openapi.OAuthFlow
+	}{
+		{"authorization_code", flows.AuthorizationCode},
+		{"implicit", flows.Implicit},
+		{"password_credentials", flows.Password},
+		{"client_credentials", flows.ClientCredentials},
+	} {
+		if candidate.flow == nil {
+			continue
+		}
+
+		attributes := []KeyValue{{Key: "grant_type", Value: candidate.grantType, Type: "string"}}
+		if candidate.flow.AuthorizationURL != "" {
+			attributes = append(attributes, KeyValue{Key: "authUrl", Value: candidate.flow.AuthorizationURL, Type: "string"})
+		}
+		if candidate.flow.TokenURL != "" {
+			attributes = append(attributes, KeyValue{Key: "accessTokenUrl", Value: candidate.flow.TokenURL, Type: "string"})
+		}
+		if len(scopes) > 0 {
+			attributes = append(attributes, KeyValue{Key: "scope", Value: strings.Join(scopes, " "), Type: "string"})
+		}
+		attributes = append(attributes,
+			KeyValue{Key: "clientId", Value: c.authVariable(name + "ClientId"), Type: "string"},
+			KeyValue{Key: "tokenName", Value: name, Type: "string"},
+		)
+		return &Auth{Type: "oauth2", Attributes: attributes}
+	}
+	return nil
+}
+
+// {{placeholder}} for a secret, declared as an empty collection variable
+func (c *converter) authVariable(name string) string {
+	if !utils.Contains(c.authVarNames, name) {
+		c.authVarNames = append(c.authVarNames, name)
+	}
+	return "{{" + name + "}}"
+}
+
+func (c *converter) authVariables() []KeyValue {
+	variables := []KeyValue{}
+	for _, name := range c.authVarNames {
+		variables = append(variables, KeyValue{Key: name, Value: "", Type: "string"})
+	}
+	return variables
+}
+
+func (c *converter) resolveParameter(param *openapi.Parameter) *openapi.Parameter {
+	if param.Ref == "" {
+		return param
+	}
+	name := strings.TrimPrefix(param.Ref, "#/components/parameters/")
+	if c.doc.Components == nil {
+		return nil
+	}
+	return c.doc.Components.Parameters[name]
+}
+
+func (c *converter) resolveRequestBody(body *openapi.RequestBody) *openapi.RequestBody {
+	if body == nil || body.Ref == "" {
+		return body
+	}
+	name := strings.TrimPrefix(body.Ref, "#/components/requestBodies/")
+	if c.doc.Components == nil {
+		return nil
+	}
+	return c.doc.Components.RequestBodies[name]
+}
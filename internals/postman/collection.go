@@ -0,0 +1,85 @@
+// Package postman converts an OpenAPI document into a Postman Collection v2.1.
+package postman
+
+import (
+	"encoding/json"
+
+	"github.com/fanchann/docunyan/internals/utils"
+)
+
+const schemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []Item     `json:"item"`
+	Auth     *Auth      `json:"auth,omitempty"`
+	Variable []KeyValue `json:"variable,omitempty"`
+}
+
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// a folder when Item is set, a request otherwise
+type Item struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Item        []Item   `json:"item,omitempty"`
+	Request     *Request `json:"request,omitempty"`
+}
+
+type Request struct {
+	Method      string     `json:"method"`
+	Header      []KeyValue `json:"header"`
+	Body        *Body      `json:"body,omitempty"`
+	URL         URL        `json:"url"`
+	Auth        *Auth      `json:"auth,omitempty"`
+	Description string     `json:"description,omitempty"`
+}
+
+type URL struct {
+	Raw      string     `json:"raw"`
+	Host     []string   `json:"host"`
+	Path     []string   `json:"path,omitempty"`
+	Query    []KeyValue `json:"query,omitempty"`
+	Variable []KeyValue `json:"variable,omitempty"`
+}
+
+type Body struct {
+	Mode    string       `json:"mode"`
+	Raw     string       `json:"raw"`
+	Options *BodyOptions `json:"options,omitempty"`
+}
+
+type BodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// header, query parameter, path variable, collection variable or auth attribute
+type KeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// auth settings, the attributes are stored under the name of the type,
+// e.g. {"type": "bearer", "bearer": [{"key": "token", ...}]}
+type Auth struct {
+	Type       string
+	Attributes []KeyValue
+}
+
+func (a Auth) MarshalJSON() ([]byte, error) {
+	auth := utils.NewOrderedMap()
+	auth.Set("type", a.Type)
+	if a.Type != "noauth" {
+		auth.Set(a.Type, a.Attributes)
+	}
+	return json.Marshal(auth)
+}
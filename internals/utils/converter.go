@@ -155,6 +155,18 @@ func ConvertToStringSlice(a []interface{}) []interface{} {
 	return result
 }
 
+// converts a decoded YAML value into a JSON friendly value
+func ConvertYAMLValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		return ConvertToStringMap(val)
+	case []interface{}:
+		return ConvertToStringSlice(val)
+	default:
+		return v
+	}
+}
+
 func ExprToTypeString(expr ast.Expr) string {
 	switch t := expr.(type) {
 	case *ast.Ident:
@@ -108,3 +108,82 @@ func InferType(val string) interface{} {
 	// Default to string
 	return val
 }
+
+// derives an operationId from the HTTP method and path
+// e.g., GET "/products" -> "listProducts", GET "/products/:id" -> "getProductsById"
+func GenerateOperationID(method, path string) string {
+	words := []string{}
+	params := []string{}
+	lastIsParam := false
+
+	for _, part := range strings.Split(path, "/") {
+		if part == "" {
+			continue
+		}
+
+		lastIsParam = strings.HasPrefix(part, ":") || (strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"))
+		if lastIsParam {
+			params = append(params, ToPascalCase(strings.Trim(part, ":{}")))
+			continue
+		}
+		words = append(words, ToPascalCase(part))
+	}
+
+	var verb string
+	switch strings.ToLower(method) {
+	case "get":
+		verb = "get"
+		if !lastIsParam && len(words) > 0 {
+			verb = "list"
+		}
+	case "post":
+		verb = "create"
+	case "put":
+		verb = "update"
+	default:
+		verb = strings.ToLower(method)
+	}
+
+	if len(words) == 0 {
+		words = append(words, "Root")
+	}
+
+	operationID := verb + strings.Join(words, "")
+	if len(params) > 0 {
+		operationID += "By" + strings.Join(params, "And")
+	}
+	return operationID
+}
+
+// converts kebab, snake or dotted names to PascalCase
+// e.g., "order-items" -> "OrderItems"
+func ToPascalCase(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	})
+
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// extracts {variable} names from a URL template
+// e.g., "https://{region}.api.example.com/{basePath}" -> ["region", "basePath"]
+func ExtractTemplateVars(template string) []string {
+	vars := []string{}
+	for {
+		start := strings.Index(template, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(template[start:], "}")
+		if end < 0 {
+			break
+		}
+		vars = append(vars, template[start+1:start+end])
+		template = template[start+end+1:]
+	}
+	return vars
+}
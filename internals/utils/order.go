@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// HTTP methods in the order of the OpenAPI path item object
+var HTTPMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// keys of a map in sorted order, so output does not depend on map iteration
+func SortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// method keys of a map in the order of HTTPMethods, other keys follow sorted
+func SortedMethods[T any](m map[string]T) []string {
+	rank := func(method string) int {
+		for i, m := range HTTPMethods {
+			if strings.ToLower(method) == m {
+				return i
+			}
+		}
+		return len(HTTPMethods)
+	}
+
+	methods := SortedKeys(m)
+	sort.SliceStable(methods, func(i, j int) bool {
+		return rank(methods[i]) < rank(methods[j])
+	})
+	return methods
+}
+
+// whether a key names an HTTP method, in any case
+func IsHTTPMethod(key string) bool {
+	return Contains(HTTPMethods, strings.ToLower(key))
+}
+
+func Contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package utils
+
+import "strings"
+
+// returns the candidate closest to word, or "" when none is close enough
+// e.g., "respones" with ["responses", "summary"] -> "responses"
+func ClosestMatch(word string, candidates []string) string {
+	best := ""
+	bestDistance := -1
+	lowerWord := strings.ToLower(word)
+
+	for _, candidate := range candidates {
+		lowerCandidate := strings.ToLower(candidate)
+		if lowerCandidate == lowerWord {
+			return candidate
+		}
+
+		distance := levenshtein(lowerWord, lowerCandidate)
+		if bestDistance < 0 || distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	// allow roughly one typo per three characters, at least two
+	limit := len(word) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	if bestDistance < 0 || bestDistance > limit {
+		return ""
+	}
+	return best
+}
+
+// edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
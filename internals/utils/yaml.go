@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// re-encodes a JSON document as YAML, keeping the key order of the JSON input
+func JSONToYAML(data []byte) ([]byte, error) {
+	// JSON is valid YAML, decoding into a node keeps the document order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	clearStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drops the flow and quoting styles of the JSON input so the output reads as block YAML
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && bytes.ContainsRune([]byte(node.Value), '\n') {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// decodes a YAML (or JSON) document keeping the key order of its mappings.
+// mappings become *OrderedMap and sequences []interface{}
+func DecodeOrderedYAML(data []byte) (interface{}, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if len(node.Content) == 0 {
+		return nil, nil
+	}
+	return decodeOrderedNode(node.Content[0])
+}
+
+func decodeOrderedNode(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		object := NewOrderedMap()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := decodeOrderedNode(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			object.Set(node.Content[i].Value, value)
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		array := []interface{}{}
+		for _, item := range node.Content {
+			value, err := decodeOrderedNode(item)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	case yaml.AliasNode:
+		return decodeOrderedNode(node.Alias)
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// position of a key in a mapping's content, -1 when missing
+func KeyIndex(mapping *yaml.Node, key string) int {
+	if mapping.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// returns the key and value nodes of a mapping entry
+func FindKey(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	index := KeyIndex(mapping, key)
+	if index < 0 {
+		return nil, nil
+	}
+	return mapping.Content[index], mapping.Content[index+1]
+}
@@ -1,18 +1,23 @@
 package watcher
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/fsnotify/fsnotify"
-	"gopkg.in/yaml.v3"
 
+	"github.com/fanchann/docunyan/internals/builder"
 	"github.com/fanchann/docunyan/internals/models"
+	"github.com/fanchann/docunyan/internals/parser"
+	"github.com/fanchann/docunyan/internals/utils"
 )
 
 type ValidationError struct {
@@ -25,9 +30,12 @@ type ConfigWatcher struct {
 	filePath string
 	watcher  *fsnotify.Watcher
 	errors   []ValidationError
+	warnings []ValidationError
+	included []string // files pulled in through include directives
+	lenient  bool     // unknown keys are warnings instead of errors
 }
 
-func NewConfigWatcher(filePath string) (*ConfigWatcher, error) {
+func NewConfigWatcher(filePath string, lenient bool) (*ConfigWatcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create watcher: %v", err)
@@ -37,6 +45,8 @@ func NewConfigWatcher(filePath string) (*ConfigWatcher, error) {
 		filePath: filePath,
 		watcher:  watcher,
 		errors:   make([]ValidationError, 0),
+		warnings: make([]ValidationError, 0),
+		lenient:  lenient,
 	}, nil
 }
 
@@ -69,7 +79,7 @@ func (c *ConfigWatcher) Start() error {
 				return fmt.Errorf("watcher event channel closed")
 			}
 
-			if filepath.Clean(event.Name) == filepath.Clean(c.filePath) {
+			if c.isWatched(event.Name) {
 				if event.Op&fsnotify.Write == fsnotify.Write || event.Op&fsnotify.Create == fsnotify.Create {
 					os.Stdout.WriteString("\033[H\033[2J")
 					color.HiCyan("📝 Docunyan YAML Config Watcher")
@@ -90,6 +100,80 @@ func (c *ConfigWatcher) Start() error {
 	}
 }
 
+// reports keys that do not match the config models, with suggestions for typos
+func (c *ConfigWatcher) validateUnknownKeys(source *parser.ConfigSource) {
+	for _, unknown := range parser.CheckUnknownKeys(source) {
+		issue := ValidationError{Field: unknown.Path}
+
+		message := fmt.Sprintf("Unknown key '%s'", unknown.Key)
+		if unknown.Suggestion != "" {
+			message += fmt.Sprintf(" (did you mean '%s'?)", unknown.Suggestion)
+		}
+
+		if absPath, err := filepath.Abs(unknown.File); err == nil && absPath == c.filePath {
+			issue.Line = unknown.Line
+		} else {
+			message += fmt.Sprintf(" in %s:%d", unknown.File, unknown.Line)
+		}
+		issue.Message = message
+
+		if c.lenient {
+			c.warnings = append(c.warnings, issue)
+		} else {
+			c.errors = append(c.errors, issue)
+		}
+	}
+}
+
+// checks that every profile applies cleanly on top of the base config
+func (c *ConfigWatcher) validateProfiles(profiles []string) {
+	for _, profile := range profiles {
+		source, err := parser.LoadConfig(c.filePath, parser.Options{Profile: profile})
+		if err == nil {
+			var doc models.DocunyanYAML
+			err = source.Root.Decode(&doc)
+		}
+		if err == nil {
+			continue
+		}
+
+		issue := ValidationError{
+			Message: fmt.Sprintf("Profile '%s': %v", profile, err),
+			Field:   "profiles." + profile,
+		}
+		// variables of a profile are often only set where that profile is used
+		var missing *parser.MissingEnvError
+		if errors.As(err, &missing) {
+			c.warnings = append(c.warnings, issue)
+		} else {
+			c.errors = append(c.errors, issue)
+		}
+	}
+}
+
+// reports whether a changed file is the config or one of its includes
+func (c *ConfigWatcher) isWatched(name string) bool {
+	if filepath.Clean(name) == filepath.Clean(c.filePath) {
+		return true
+	}
+	for _, file := range c.included {
+		if filepath.Clean(name) == filepath.Clean(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// starts watching the directories of included files
+func (c *ConfigWatcher) watchIncluded(files []string) {
+	c.included = files
+	for _, file := range files {
+		if err := c.watcher.Add(filepath.Dir(file)); err != nil {
+			color.Red("Watcher error: %v", err)
+		}
+	}
+}
+
 func (c *ConfigWatcher) Close() {
 	if c.watcher != nil {
 		c.watcher.Close()
@@ -106,9 +190,18 @@ func (c *ConfigWatcher) validateFile() {
 	}
 
 	c.errors = make([]ValidationError, 0)
+	c.warnings = make([]ValidationError, 0)
 
 	var doc models.DocunyanYAML
-	err = yaml.Unmarshal(content, &doc)
+	source, err := parser.LoadConfig(c.filePath, parser.Options{})
+	if err == nil {
+		c.watchIncluded(source.Files)
+		err = source.Root.Decode(&doc)
+	}
+	if err == nil {
+		c.validateUnknownKeys(source)
+		c.validateProfiles(source.Profiles)
+	}
 	if err != nil {
 		errorMsg := err.Error()
 		lineNum := 0
@@ -183,6 +276,21 @@ func (c *ConfigWatcher) validateFile() {
 		if doc.Authorization != nil {
 			color.White("Auth Type: %s (%s)", doc.Authorization.Name, strings.Join(doc.Authorization.Type, ", "))
 		}
+		if len(doc.SecuritySchemes) > 0 {
+			color.White("Security Schemes: %d", len(doc.SecuritySchemes))
+		}
+	}
+
+	if len(c.warnings) > 0 {
+		fmt.Println()
+		color.Yellow("⚠️  %d warnings:", len(c.warnings))
+		for i, warning := range c.warnings {
+			if warning.Line > 0 {
+				color.Yellow("%d. Line %d: %s", i+1, warning.Line, warning.Message)
+			} else {
+				color.Yellow("%d. Field '%s': %s", i+1, warning.Field, warning.Message)
+			}
+		}
 	}
 
 	elapsed := time.Since(start)
@@ -203,6 +311,22 @@ func (c *ConfigWatcher) validateSchema(doc *models.DocunyanYAML, content []byte)
 		c.addError("info.version", "Version is required", lineMap)
 	}
 
+	if doc.Info.License != nil && doc.Info.License.Name == "" {
+		c.addError("info.license.name", "License name is required", lineMap)
+	}
+
+	if doc.Info.License != nil && doc.Info.License.Identifier != "" && doc.Info.License.URL != "" {
+		c.addError("info.license.identifier", "License identifier and url are mutually exclusive", lineMap)
+	}
+
+	if doc.Info.Contact != nil && doc.Info.Contact.Email != "" && !strings.Contains(doc.Info.Contact.Email, "@") {
+		c.addError("info.contact.email", fmt.Sprintf("Invalid email address: %s", doc.Info.Contact.Email), lineMap)
+	}
+
+	if doc.Info.Logo != nil && doc.Info.Logo.URL == "" {
+		c.addError("info.x-logo.url", "Logo URL is required", lineMap)
+	}
+
 	if len(doc.Paths) == 0 {
 		c.addError("paths", "At least one path must be defined", lineMap)
 	}
@@ -214,83 +338,84 @@ func (c *ConfigWatcher) validateSchema(doc *models.DocunyanYAML, content []byte)
 			if server.URL == "" {
 				c.addError(fmt.Sprintf("servers[%d].url", i), "Server URL is required", lineMap)
 			}
+
+			for _, name := range utils.ExtractTemplateVars(server.URL) {
+				if _, ok := server.Variables[name]; !ok {
+					c.addError(fmt.Sprintf("servers[%d].url", i), fmt.Sprintf("Server variable '%s' is not defined", name), lineMap)
+				}
+			}
+
+			for name, variable := range server.Variables {
+				field := fmt.Sprintf("servers[%d].variables.%s", i, name)
+				if variable.Default == "" {
+					c.addError(field+".default", "Server variable default is required", lineMap)
+				} else if len(variable.Enum) > 0 && !utils.Contains(variable.Enum, variable.Default) {
+					c.addError(field+".default", fmt.Sprintf("Default '%s' is not one of the enum values", variable.Default), lineMap)
+				}
+			}
 		}
 	}
 
-	for path, methods := range doc.Paths {
+	c.validateTags(doc, lineMap)
+	c.validateSecuritySchemes(doc, lineMap)
+
+	operationIDs := map[string]string{}
+
+	for path, item := range doc.Paths {
 		if !strings.HasPrefix(path, "/") {
 			c.addError(fmt.Sprintf("paths.%s", path), "Path must start with '/'", lineMap)
 		}
 
-		for method, detail := range methods {
-			validMethods := []string{"get", "post", "put", "delete", "patch", "options", "head", "trace"}
-			isValid := false
-			for _, valid := range validMethods {
-				if strings.ToLower(method) == valid {
-					isValid = true
-					break
-				}
-			}
+		for i, param := range item.Parameters {
+			c.validateParameter(fmt.Sprintf("paths.%s.parameters[%d]", path, i), param, doc.Components, lineMap)
+		}
+
+		for method, detail := range item.Operations {
+			c.validateOperation(fmt.Sprintf("paths.%s.%s", path, method), fmt.Sprintf("%s %s", strings.ToUpper(method), path),
+				detail, doc, operationIDs, false, lineMap)
+		}
+	}
+
+	for name, item := range doc.Webhooks {
+		for method, detail := range item.Operations {
+			c.validateOperation(fmt.Sprintf("webhooks.%s.%s", name, method), fmt.Sprintf("webhook %s %s", strings.ToUpper(method), name),
+				detail, doc, operationIDs, true, lineMap)
+		}
+	}
+
+	// generated ids go through the same assignment as the build, explicit duplicates
+	// are reported per operation above
+	if _, renamed, err := builder.AssignOperationIDs(*doc); err == nil {
+		for _, rename := range renamed {
+			c.addWarning(fmt.Sprintf("paths.%s.%s", rename.Path, rename.Method),
+				fmt.Sprintf("Generated operationId '%s' is already used by %s, renamed to '%s'", rename.Generated, rename.TakenBy, rename.OperationID), lineMap)
+		}
+	}
 
-			if !isValid {
-				c.addError(fmt.Sprintf("paths.%s.%s", path, method),
-					fmt.Sprintf("Invalid HTTP method: %s", method), lineMap)
+	if doc.Components != nil {
+		for name, param := range doc.Components.Parameters {
+			if param.Ref != "" {
+				c.addError(fmt.Sprintf("components.parameters.%s", name), "Components cannot reference other components", lineMap)
+				continue
 			}
+			c.validateParameter(fmt.Sprintf("components.parameters.%s", name), param, doc.Components, lineMap)
+		}
 
-			if len(detail.Responses) == 0 {
-				c.addError(fmt.Sprintf("paths.%s.%s.responses", path, method),
-					"At least one response must be defined", lineMap)
-			} else {
-				for status, response := range detail.Responses {
-					if !isValidStatusCode(status) {
-						c.addError(fmt.Sprintf("paths.%s.%s.responses.%s", path, method, status),
-							fmt.Sprintf("Invalid status code: %s", status), lineMap)
-					}
-
-					if response.Description == "" {
-						c.addError(fmt.Sprintf("paths.%s.%s.responses.%s.description", path, method, status),
-							"Description is required", lineMap)
-					}
-
-					if response.Schema == "" {
-						c.addError(fmt.Sprintf("paths.%s.%s.responses.%s.schema", path, method, status),
-							"Schema is required", lineMap)
-					}
-				}
+		for name, response := range doc.Components.Responses {
+			if response.Ref != "" {
+				c.addError(fmt.Sprintf("components.responses.%s", name), "Components cannot reference other components", lineMap)
+				continue
 			}
+			c.validateResponse(fmt.Sprintf("components.responses.%s", name), response, doc.Components, lineMap)
+			c.validateLinks(fmt.Sprintf("components.responses.%s.links", name), response.Links, doc, lineMap)
+		}
 
-			if detail.Parameters != nil {
-				for i, param := range detail.Parameters {
-					if param.Name == "" {
-						c.addError(fmt.Sprintf("paths.%s.%s.parameters[%d].name", path, method, i),
-							"Parameter name is required", lineMap)
-					}
-
-					if param.In == "" {
-						c.addError(fmt.Sprintf("paths.%s.%s.parameters[%d].in", path, method, i),
-							"Parameter location (in) is required", lineMap)
-					} else {
-						validLocations := []string{"query", "path", "header", "cookie"}
-						isValid := false
-						for _, valid := range validLocations {
-							if strings.ToLower(param.In) == valid {
-								isValid = true
-								break
-							}
-						}
-
-						if !isValid {
-							c.addError(fmt.Sprintf("paths.%s.%s.parameters[%d].in", path, method, i),
-								fmt.Sprintf("Invalid 'in' value: %s", param.In), lineMap)
-						}
-					}
-
-					if strings.ToLower(param.In) == "path" && !param.Required {
-						c.addError(fmt.Sprintf("paths.%s.%s.parameters[%d].required", path, method, i),
-							"Path parameters must be required", lineMap)
-					}
-				}
+		for name, body := range doc.Components.RequestBodies {
+			if body.Ref != "" {
+				c.addError(fmt.Sprintf("components.requestBodies.%s", name), "Components cannot reference other components", lineMap)
+				continue
 			}
+			c.validateRequestBody(fmt.Sprintf("components.requestBodies.%s", name), body, doc.Components, lineMap)
 		}
 	}
 
@@ -309,6 +434,401 @@ func (c *ConfigWatcher) validateSchema(doc *models.DocunyanYAML, content []byte)
 	}
 }
 
+// validates a single operation. outgoing operations (webhooks and callbacks) may answer
+// without a response body but should describe the payload they send
+func (c *ConfigWatcher) validateOperation(field, owner string, detail models.EndpointDetail, doc *models.DocunyanYAML, operationIDs map[string]string, outgoing bool, lineMap map[string]int) {
+	if detail.OperationID != "" {
+		if other, exists := operationIDs[detail.OperationID]; exists {
+			c.addError(field+".operationId",
+				fmt.Sprintf("Duplicate operationId '%s' (also used by %s)", detail.OperationID, other), lineMap)
+		} else {
+			operationIDs[detail.OperationID] = owner
+		}
+	}
+
+	c.validateSecurityRequirements(field+".security", doc, detail.Security, lineMap)
+
+	if detail.ExternalDocs != nil && detail.ExternalDocs.URL == "" {
+		c.addError(field+".externalDocs.url", "External docs URL is required", lineMap)
+	}
+
+	if len(detail.Responses) == 0 {
+		c.addError(field+".responses", "At least one response must be defined", lineMap)
+	} else {
+		for status, response := range detail.Responses {
+			if !isValidStatusCode(status) {
+				c.addError(fmt.Sprintf("%s.responses.%s", field, status),
+					fmt.Sprintf("Invalid status code: %s", status), lineMap)
+			}
+
+			c.validateLinks(fmt.Sprintf("%s.responses.%s.links", field, status), response.Links, doc, lineMap)
+
+			if outgoing && response.Ref == "" && response.Schema == "" {
+				if response.Description == "" {
+					c.addError(fmt.Sprintf("%s.responses.%s.description", field, status), "Description is required", lineMap)
+				}
+				continue
+			}
+
+			c.validateResponse(fmt.Sprintf("%s.responses.%s", field, status), response, doc.Components, lineMap)
+		}
+	}
+
+	if detail.RequestBody != nil {
+		c.validateRequestBody(field+".requestBody", *detail.RequestBody, doc.Components, lineMap)
+	} else if outgoing {
+		c.addWarning(field+".requestBody", "Outgoing request has no payload schema", lineMap)
+	}
+
+	for i, param := range detail.Parameters {
+		c.validateParameter(fmt.Sprintf("%s.parameters[%d]", field, i), param, doc.Components, lineMap)
+	}
+
+	for name, callback := range detail.Callbacks {
+		if len(callback) == 0 {
+			c.addError(fmt.Sprintf("%s.callbacks.%s", field, name), "Callback must define at least one expression", lineMap)
+		}
+		for expression, item := range callback {
+			for method, callbackDetail := range item.Operations {
+				c.validateOperation(fmt.Sprintf("%s.callbacks.%s.%s.%s", field, name, expression, method),
+					fmt.Sprintf("%s callback %s: %s %s", owner, name, strings.ToUpper(method), expression),
+					callbackDetail, doc, operationIDs, true, lineMap)
+			}
+		}
+	}
+}
+
+// checks that links point at an operation declared under paths
+func (c *ConfigWatcher) validateLinks(field string, links map[string]models.Link, doc *models.DocunyanYAML, lineMap map[string]int) {
+	if len(links) == 0 {
+		return
+	}
+
+	// the ids the build assigns, including suffixed generated ones. with explicit
+	// duplicates, reported per operation, the ids are unknown and not checked
+	assigned, _, err := builder.AssignOperationIDs(*doc)
+	knownIDs := err == nil
+	operationIDs := []string{}
+	for _, methods := range assigned {
+		for _, operationID := range methods {
+			operationIDs = append(operationIDs, operationID)
+		}
+	}
+	sort.Strings(operationIDs)
+
+	for name, link := range links {
+		linkField := fmt.Sprintf("%s.%s", field, name)
+		switch {
+		case link.OperationID == "" && link.OperationRef == "":
+			c.addError(linkField, "Link requires operationId or operationRef", lineMap)
+		case link.OperationID != "" && link.OperationRef != "":
+			c.addError(linkField, "Link cannot set both operationId and operationRef", lineMap)
+		case link.OperationID != "" && knownIDs && !utils.Contains(operationIDs, link.OperationID):
+			message := fmt.Sprintf("Unknown operationId: %s", link.OperationID)
+			if suggestion := utils.ClosestMatch(link.OperationID, operationIDs); suggestion != "" {
+				message += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+			}
+			c.addError(linkField+".operationId", message, lineMap)
+		case link.OperationRef != "":
+			method, path, found := strings.Cut(strings.TrimSpace(link.OperationRef), " ")
+			if !found || strings.HasPrefix(link.OperationRef, "#") {
+				continue
+			}
+			if !hasOperation(doc, strings.TrimSpace(path), method) {
+				c.addError(linkField+".operationRef", fmt.Sprintf("Unknown operation: %s", link.OperationRef), lineMap)
+			}
+		}
+	}
+}
+
+// whether a path, written as /orders/:id or /orders/{id}, declares the method
+func hasOperation(doc *models.DocunyanYAML, path, method string) bool {
+	configPath, ok := builder.FindConfigPath(*doc, path)
+	if !ok {
+		return false
+	}
+	for declared := range doc.Paths[configPath].Operations {
+		if strings.EqualFold(declared, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ConfigWatcher) validateResponse(field string, response models.Response, components *models.Components, lineMap map[string]int) {
+	if response.Ref != "" {
+		if components == nil || !hasKey(components.Responses, response.Ref) {
+			c.addError(field+".$ref", fmt.Sprintf("Unknown response component: %s", response.Ref), lineMap)
+		}
+		return
+	}
+
+	if response.Description == "" {
+		c.addError(field+".description", "Description is required", lineMap)
+	}
+
+	if response.Schema == "" {
+		c.addError(field+".schema", "Schema is required", lineMap)
+	}
+
+	for name, header := range response.Headers {
+		if header.Ref != "" && (components == nil || !hasKey(components.Headers, header.Ref)) {
+			c.addError(fmt.Sprintf("%s.headers.%s.$ref", field, name), fmt.Sprintf("Unknown header component: %s", header.Ref), lineMap)
+		}
+	}
+
+	c.validateExamples(field, response.Example, response.Examples, lineMap)
+}
+
+func (c *ConfigWatcher) validateRequestBody(field string, body models.RequestBody, components *models.Components, lineMap map[string]int) {
+	if body.Ref != "" {
+		if components == nil || !hasKey(components.RequestBodies, body.Ref) {
+			c.addError(field+".$ref", fmt.Sprintf("Unknown request body component: %s", body.Ref), lineMap)
+		}
+		return
+	}
+
+	if body.Schema == "" {
+		c.addError(field+".schema", "Request body schema is required", lineMap)
+	}
+
+	c.validateExamples(field, body.Example, body.Examples, lineMap)
+}
+
+func (c *ConfigWatcher) validateParameter(field string, param models.Parameter, components *models.Components, lineMap map[string]int) {
+	if param.Ref != "" {
+		if components == nil || !hasKey(components.Parameters, param.Ref) {
+			c.addError(field+".$ref", fmt.Sprintf("Unknown parameter component: %s", param.Ref), lineMap)
+		}
+		return
+	}
+
+	if param.Name == "" {
+		c.addError(field+".name", "Parameter name is required", lineMap)
+	}
+
+	if param.In == "" {
+		c.addError(field+".in", "Parameter location (in) is required", lineMap)
+	} else {
+		validLocations := []string{"query", "path", "header", "cookie"}
+		isValid := false
+		for _, valid := range validLocations {
+			if strings.ToLower(param.In) == valid {
+				isValid = true
+				break
+			}
+		}
+
+		if !isValid {
+			c.addError(field+".in", fmt.Sprintf("Invalid 'in' value: %s", param.In), lineMap)
+		}
+	}
+
+	if strings.ToLower(param.In) == "path" && !param.Required {
+		c.addError(field+".required", "Path parameters must be required", lineMap)
+	}
+}
+
+// checks that example files referenced by a response or request body exist
+func (c *ConfigWatcher) validateExamples(field string, example interface{}, examples map[string]models.Example, lineMap map[string]int) {
+	if file, ok := example.(string); ok && strings.HasSuffix(strings.ToLower(file), ".json") {
+		c.validateExampleFile(field+".example", file, lineMap)
+	}
+
+	for name, ex := range examples {
+		exampleField := fmt.Sprintf("%s.examples.%s", field, name)
+		if ex.File != "" {
+			c.validateExampleFile(exampleField, ex.File, lineMap)
+		} else if ex.Value == nil && ex.ExternalValue == "" {
+			c.addError(exampleField, "Example requires value, file or externalValue", lineMap)
+		}
+	}
+}
+
+func (c *ConfigWatcher) validateExampleFile(field, file string, lineMap map[string]int) {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(c.filePath), path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		c.addError(field, fmt.Sprintf("Example file not found: %s", file), lineMap)
+		return
+	}
+
+	if !json.Valid(content) {
+		c.addError(field, fmt.Sprintf("Example file is not valid JSON: %s", file), lineMap)
+	}
+}
+
+// checks the named security schemes and the global security requirements
+func (c *ConfigWatcher) validateSecuritySchemes(doc *models.DocunyanYAML, lineMap map[string]int) {
+	for name, scheme := range doc.SecuritySchemes {
+		field := fmt.Sprintf("securitySchemes.%s", name)
+
+		switch strings.ToLower(scheme.Type) {
+		case "":
+			c.addError(field+".type", "Security scheme type is required", lineMap)
+		case "http":
+			if scheme.Scheme == "" {
+				c.addError(field+".scheme", "HTTP security scheme requires scheme (e.g. bearer, basic)", lineMap)
+			} else if !utils.Contains(httpAuthSchemes, strings.ToLower(scheme.Scheme)) {
+				c.addWarning(field+".scheme", fmt.Sprintf("Unregistered HTTP authentication scheme: %s", scheme.Scheme), lineMap)
+			}
+			if scheme.BearerFormat != "" && strings.ToLower(scheme.Scheme) != "bearer" {
+				c.addError(field+".bearerFormat", "bearerFormat is only valid for the bearer scheme", lineMap)
+			}
+		case "apikey":
+			if scheme.Name == "" {
+				c.addError(field+".name", "API key security scheme requires name", lineMap)
+			}
+			if scheme.In != "" && !utils.Contains([]string{"header", "query", "cookie"}, strings.ToLower(scheme.In)) {
+				c.addError(field+".in", fmt.Sprintf("Invalid 'in' value: %s", scheme.In), lineMap)
+			}
+		case "oauth2":
+			c.validateOAuthFlows(field+".flows", scheme.Flows, lineMap)
+		case "openidconnect":
+			if scheme.OpenIDConnectURL == "" {
+				c.addError(field+".openIdConnectUrl", "OpenID Connect security scheme requires openIdConnectUrl", lineMap)
+			}
+		case "mutualtls":
+		default:
+			c.addError(field+".type", fmt.Sprintf("Unsupported security scheme type: %s (expected http, apiKey, oauth2, openIdConnect or mutualTLS)", scheme.Type), lineMap)
+		}
+
+		if strings.ToLower(scheme.Type) != "oauth2" && scheme.Flows != nil {
+			c.addError(field+".flows", "flows are only valid for oauth2 security schemes", lineMap)
+		}
+	}
+
+	if doc.Authorization != nil {
+		for _, authType := range doc.Authorization.Type {
+			if !utils.Contains([]string{"http", "apikey"}, strings.ToLower(authType)) {
+				c.addError("authorization.type", fmt.Sprintf("Unsupported authorization type: %s (declare it under securitySchemes)", authType), lineMap)
+			}
+		}
+	}
+
+	c.validateSecurityRequirements("security", doc, doc.Security, lineMap)
+}
+
+// checks that required schemes exist and that requested oauth2 scopes are declared
+func (c *ConfigWatcher) validateSecurityRequirements(field string, doc *models.DocunyanYAML, requirements []models.SecurityRequirement, lineMap map[string]int) {
+	for _, requirement := range requirements {
+		seen := map[string]bool{}
+		for _, required := range requirement {
+			if seen[required.Name] {
+				c.addError(field, fmt.Sprintf("Security scheme %s is listed twice in one requirement", required.Name), lineMap)
+			}
+			seen[required.Name] = true
+
+			if !c.hasSecurityScheme(doc, required.Name) {
+				c.addError(field, fmt.Sprintf("Unknown security scheme: %s", required.Name), lineMap)
+				continue
+			}
+
+			scheme, ok := doc.SecuritySchemes[required.Name]
+			if !ok || strings.ToLower(scheme.Type) != "oauth2" {
+				continue
+			}
+			for _, scope := range required.Scopes {
+				if !scheme.HasScope(scope) {
+					c.addError(field, fmt.Sprintf("Scope '%s' is not declared by security scheme %s", scope, required.Name), lineMap)
+				}
+			}
+		}
+	}
+}
+
+func (c *ConfigWatcher) validateOAuthFlows(field string, flows *models.OAuthFlows, lineMap map[string]int) {
+	_, err := builder.BuildOAuthFlows(flows)
+	if err == nil {
+		return
+	}
+
+	// a missing URL is reported at the key of the flow that lacks it
+	var missing *builder.MissingFlowURLError
+	if errors.As(err, &missing) {
+		field = fmt.Sprintf("%s.%s.%s", field, missing.Flow, missing.Key)
+	}
+	c.addError(field, fmt.Sprintf("Invalid OAuth2 flows: %v", err), lineMap)
+}
+
+// looks the name up in securitySchemes and in the keys generated for the legacy authorization block
+func (c *ConfigWatcher) hasSecurityScheme(doc *models.DocunyanYAML, name string) bool {
+	if _, ok := doc.SecuritySchemes[name]; ok {
+		return true
+	}
+
+	if doc.Authorization != nil {
+		for i := range doc.Authorization.Type {
+			if key, _ := builder.LegacySecurityKey(*doc.Authorization, i); key == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checks tag declarations and reports endpoints using undeclared tags
+func (c *ConfigWatcher) validateTags(doc *models.DocunyanYAML, lineMap map[string]int) {
+	declared := map[string]bool{}
+	for i, tag := range doc.Tags {
+		if tag.Name == "" {
+			c.addError(fmt.Sprintf("tags[%d].name", i), "Tag name is required", lineMap)
+			continue
+		}
+		if declared[tag.Name] {
+			c.addError(fmt.Sprintf("tags[%d].name", i), fmt.Sprintf("Duplicate tag: %s", tag.Name), lineMap)
+		}
+		declared[tag.Name] = true
+	}
+
+	for i, group := range doc.TagGroups {
+		if group.Name == "" {
+			c.addError(fmt.Sprintf("tagGroups[%d].name", i), "Tag group name is required", lineMap)
+		}
+		for _, tag := range group.Tags {
+			if len(doc.Tags) > 0 && !declared[tag] {
+				c.addWarning(fmt.Sprintf("tagGroups[%d].tags", i), fmt.Sprintf("Tag group uses undeclared tag: %s", tag), lineMap)
+			}
+		}
+	}
+
+	if len(doc.Tags) == 0 {
+		return
+	}
+
+	for path, item := range doc.Paths {
+		for method, detail := range item.Operations {
+			for _, tag := range detail.Tags {
+				if !declared[tag] {
+					c.addWarning(fmt.Sprintf("paths.%s.%s.tags", path, method), fmt.Sprintf("Undeclared tag: %s", tag), lineMap)
+				}
+			}
+		}
+	}
+
+	for name, item := range doc.Webhooks {
+		for method, detail := range item.Operations {
+			for _, tag := range detail.Tags {
+				if !declared[tag] {
+					c.addWarning(fmt.Sprintf("webhooks.%s.%s.tags", name, method), fmt.Sprintf("Undeclared tag: %s", tag), lineMap)
+				}
+			}
+		}
+	}
+}
+
+func (c *ConfigWatcher) addWarning(field, message string, lineMap map[string]int) {
+	c.warnings = append(c.warnings, ValidationError{
+		Message: message,
+		Line:    lineMap[field],
+		Field:   field,
+	})
+}
+
 func (c *ConfigWatcher) addError(field, message string, lineMap map[string]int) {
 	lineNum := lineMap[field]
 	c.errors = append(c.errors, ValidationError{
@@ -427,10 +947,21 @@ func isValidStatusCode(status string) bool {
 	return prefix >= '1' && prefix <= '5'
 }
 
-func countEndpoints(paths map[string]map[string]models.EndpointDetail) int {
+func countEndpoints(paths map[string]models.PathItem) int {
 	count := 0
-	for _, methods := range paths {
-		count += len(methods)
+	for _, item := range paths {
+		count += len(item.Operations)
 	}
 	return count
 }
+
+// schemes from the IANA HTTP Authentication Scheme Registry
+var httpAuthSchemes = []string{"basic", "bearer", "digest", "hoba", "mutual", "negotiate", "oauth", "scram-sha-1", "scram-sha-256", "vapid"}
+
+func hasKey[T any](m map[string]T, key string) bool {
+	if strings.HasPrefix(key, "#") {
+		return true
+	}
+	_, ok := m[key]
+	return ok
+}